@@ -1,7 +1,9 @@
 package sqlexpr
 
 import (
+	"database/sql"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -10,17 +12,134 @@ type Expr interface {
 }
 
 type Builder struct {
-	buf  strings.Builder
-	args []interface{}
-	last rune
+	dialect    *Dialect
+	buf        strings.Builder
+	args       []interface{}
+	argIndexes map[interface{}]int
+	namedArgs  map[string]interface{}
+	reuseArgs  bool
+	inlineArgs bool
+	indent     string
+	depth      int
+	newline    bool
+	lineDepth  int
+	last       rune
+	errs       []error
 }
 
+// NewBuilder returns a Builder that renders SQL for the given dialect. A nil
+// dialect, like a zero Builder, uses the default set via SetDialect.
+func NewBuilder(d *Dialect) *Builder {
+	return &Builder{dialect: d}
+}
+
+// Build renders the expression using the default dialect (see SetDialect).
 func Build(e Expr) (sql string, args []interface{}) {
-	var b Builder
+	return BuildWith(nil, e)
+}
+
+// BuildWith renders the expression using the given dialect.
+func BuildWith(d *Dialect, e Expr) (sql string, args []interface{}) {
+	b := NewBuilder(d)
 	b.Append(e)
 	return b.SQLArgs()
 }
 
+// BuildErr is like Build, but also returns the errors reported while building.
+func BuildErr(e Expr) (sql string, args []interface{}, err error) {
+	return BuildWithErr(nil, e)
+}
+
+// BuildWithErr is like BuildWith, but also returns the errors reported while building.
+func BuildWithErr(d *Dialect, e Expr) (sql string, args []interface{}, err error) {
+	b := NewBuilder(d)
+	b.Append(e)
+	sql, args = b.SQLArgs()
+	return sql, args, b.Err()
+}
+
+// Dialect returns the dialect this builder renders SQL for.
+func (b *Builder) Dialect() *Dialect {
+	if b.dialect != nil {
+		return b.dialect
+	}
+	return defaultDialect()
+}
+
+// SetReuseArgs makes the builder bind identical string, number and bool
+// values to a single placeholder when the dialect uses numbered placeholders
+// (e.g. $1). Param values are always shared this way.
+func (b *Builder) SetReuseArgs(reuse bool) {
+	b.reuseArgs = reuse
+}
+
+// SetInlineArgs makes the builder render values as SQL literals instead of
+// placeholders (see Dialect.FormatLiteral). This is meant for debugging and
+// logging only: never execute the resulting SQL.
+func (b *Builder) SetInlineArgs(inline bool) {
+	b.inlineArgs = inline
+}
+
+// SetIndent switches the builder into multi-line mode: statements start each
+// clause on a new line, and nested conditions and subqueries are indented
+// using the given string. An empty string restores single-line output.
+func (b *Builder) SetIndent(indent string) {
+	b.indent = indent
+}
+
+// NewLine starts a new line before the next token in multi-line mode, and
+// does nothing otherwise.
+func (b *Builder) NewLine() {
+	if b.indent != "" {
+		b.newline = true
+		b.lineDepth = b.depth
+	}
+}
+
+// Indent increases the indentation of the lines started by NewLine.
+func (b *Builder) Indent() {
+	b.depth++
+}
+
+// Unindent undoes Indent.
+func (b *Builder) Unindent() {
+	b.depth--
+}
+
+// Multiline returns whether the builder is in multi-line mode (see SetIndent).
+func (b *Builder) Multiline() bool {
+	return b.indent != ""
+}
+
+// AddError records a problem with the SQL being built, e.g. a statement that
+// would render invalid SQL. Building carries on, so that all problems can be
+// reported at once.
+func (b *Builder) AddError(err error) {
+	b.errs = append(b.errs, err)
+}
+
+// Err returns the errors reported via AddError, or nil if there were none.
+func (b *Builder) Err() error {
+	switch len(b.errs) {
+	case 0:
+		return nil
+	case 1:
+		return b.errs[0]
+	default:
+		return buildErrors(b.errs)
+	}
+}
+
+type buildErrors []error
+
+func (errs buildErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
 func (b *Builder) SQLArgs() (sql string, args []interface{}) {
 	return b.SQL(), b.Args()
 }
@@ -31,16 +150,20 @@ func (b *Builder) Args() []interface{} {
 	return b.args
 }
 
-func FormatSQLArgs(sql string, args []interface{}) string {
+func FormatSQLArgs(query string, args []interface{}) string {
 	var buf strings.Builder
-	buf.WriteString(sql)
+	buf.WriteString(query)
 	if len(args) > 0 {
 		buf.WriteString(" [")
 		for i, arg := range args {
 			if i > 0 {
 				buf.WriteString(", ")
 			}
-			fmt.Fprint(&buf, arg)
+			if na, ok := arg.(sql.NamedArg); ok {
+				fmt.Fprintf(&buf, "%s=%v", na.Name, na.Value)
+			} else {
+				fmt.Fprint(&buf, arg)
+			}
 		}
 		buf.WriteString("]")
 	}
@@ -57,9 +180,9 @@ func (b *Builder) AppendRaw(s string) {
 		return
 	}
 
-	if b.last != 0 {
+	if !b.startLine() && b.last != 0 {
 		last, first := b.last, rune(s[0])
-		if (isWordChar(last) && !skipSpaceBefore(first) && !isComma(first)) || (isWordChar(first) && !skipSpaceAfter(last)) || isComma(last) {
+		if ((isWordChar(last) || last == ')') && !skipSpaceBefore(first) && !isComma(first)) || (isWordChar(first) && !skipSpaceAfter(last)) || isComma(last) || (isOperatorChar(last) && first == '(') {
 			b.buf.WriteByte(' ')
 		}
 	}
@@ -68,9 +191,32 @@ func (b *Builder) AppendRaw(s string) {
 	b.last = rune(s[len(s)-1])
 }
 
+// startLine writes a pending line break, returning whether it did.
+func (b *Builder) startLine() bool {
+	if !b.newline {
+		return false
+	}
+	b.newline = false
+	if b.last == 0 {
+		return false
+	}
+	b.buf.WriteByte('\n')
+	b.buf.WriteString(strings.Repeat(b.indent, b.lineDepth))
+	return true
+}
+
+// appendLiteral is like AppendRaw, but always separates the literal from the
+// preceding token, so that e.g. = -1 or = 'foo' don't get glued together.
+func (b *Builder) appendLiteral(s string) {
+	if !b.startLine() && b.last != 0 && !skipSpaceAfter(b.last) {
+		b.buf.WriteByte(' ')
+	}
+	b.buf.WriteString(s)
+	b.last = rune(s[len(s)-1])
+}
+
 func (b *Builder) AppendName(s string) {
-	// TODO: quote name if necessary?
-	b.AppendRaw(s)
+	b.AppendRaw(b.Dialect().QuoteName(s))
 }
 
 func (b *Builder) AppendExpr(expr Expr) {
@@ -82,21 +228,100 @@ func (b *Builder) AppendExpr(expr Expr) {
 func (b *Builder) Append(item interface{}) {
 	if expr, ok := item.(Expr); ok {
 		expr.AppendToSQLBuilder(b)
+	} else if b.reuseArgs && isReusableArg(item) {
+		b.appendArg(item, item)
 	} else {
-		placeholder := dialect.FormatPlaceholder(len(b.args))
-		b.args = append(b.args, item)
-		b.AppendRaw(placeholder)
+		b.appendArg(item, nil)
 	}
 }
 
+// appendArg adds a placeholder for the given value. Values with the same
+// non-nil key share a placeholder if the dialect numbers its placeholders.
+func (b *Builder) appendArg(v interface{}, key interface{}) {
+	d := b.Dialect()
+	if b.inlineArgs {
+		b.appendLiteral(d.FormatLiteral(v))
+		return
+	}
+	reusable := key != nil && d.ArgStyle.Numbered()
+	if reusable {
+		if index, ok := b.argIndexes[key]; ok {
+			b.appendPlaceholder(d, index)
+			return
+		}
+	}
+
+	index := len(b.args)
+	if d.ArgStyle == NamedArgs {
+		name := autoArgName(index)
+		if _, ok := b.namedArgs[name]; ok {
+			b.AddError(fmt.Errorf("sqlexpr: named argument %s collides with an automatically named one", name))
+		}
+		b.addNamedArg(name, v)
+	} else {
+		b.args = append(b.args, v)
+	}
+	if reusable {
+		if b.argIndexes == nil {
+			b.argIndexes = make(map[interface{}]int)
+		}
+		b.argIndexes[key] = index
+	}
+	b.appendPlaceholder(d, index)
+}
+
+func (b *Builder) appendPlaceholder(d *Dialect, index int) {
+	placeholder := d.FormatPlaceholder(index)
+	if placeholder == "" {
+		b.AddError(fmt.Errorf("sqlexpr: %s dialect has unsupported ArgStyle %d", d.Name, d.ArgStyle))
+		placeholder = "?"
+	}
+	b.AppendRaw(placeholder)
+}
+
+// AppendSubquery appends the statement in parentheses, putting it on its own
+// indented lines in multi-line mode.
+func (b *Builder) AppendSubquery(stmt Expr) {
+	b.AppendRaw("(")
+	b.Indent()
+	b.NewLine()
+	b.AppendExpr(stmt)
+	b.Unindent()
+	b.NewLine()
+	b.AppendRaw(")")
+}
+
 func (b *Builder) AppendAll(items ...interface{}) {
 	for _, item := range items {
 		b.Append(item)
 	}
 }
 
+func (b *Builder) addNamedArg(name string, v interface{}) {
+	if b.namedArgs == nil {
+		b.namedArgs = make(map[string]interface{})
+	}
+	b.namedArgs[name] = v
+	b.args = append(b.args, sql.Named(name, v))
+}
+
+func isReusableArg(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
 func isWordChar(r rune) bool {
-	return 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_' || r == '$'
+	return 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '_' || r == '$' || r == '?' || r == ':' || r == '@'
 }
 
 func skipSpaceAfter(r rune) bool {
@@ -104,7 +329,11 @@ func skipSpaceAfter(r rune) bool {
 }
 
 func skipSpaceBefore(r rune) bool {
-	return r == ')' || r == '.'
+	return r == ')' || r == '.' || r == ';'
+}
+
+func isOperatorChar(r rune) bool {
+	return strings.ContainsRune("=<>!+-*/%|&", r)
 }
 
 func isComma(r rune) bool {
@@ -128,6 +357,39 @@ func (v Column) AppendToSQLBuilder(b *Builder) {
 	b.AppendName(string(v))
 }
 
+// Col returns the column qualified with the table name, e.g. accounts.id.
+func (v Table) Col(c Column) Expr {
+	return Qualified(v, c)
+}
+
+// As returns the table with an alias, e.g. accounts AS a.
+func (v Table) As(alias Table) TableAlias {
+	return TableAlias{v, alias}
+}
+
+// TableAlias is a table or a subquery with an alias. It renders as
+// "table AS alias" (or "table alias", see Dialect.TableAliasAs) in FROM,
+// joins, UPDATE and DELETE; use Col to refer to its columns elsewhere.
+type TableAlias struct {
+	Table Expr
+	Alias Table
+}
+
+// Col returns the column qualified with the alias, e.g. a.id.
+func (v TableAlias) Col(c Column) Expr {
+	return Qualified(v.Alias, c)
+}
+
+func (v TableAlias) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(v.Table)
+	if v.Alias != "" {
+		if b.Dialect().TableAliasAs {
+			b.AppendRaw("AS")
+		}
+		b.AppendExpr(v.Alias)
+	}
+}
+
 const (
 	Empty     = Raw("")
 	Star      = Raw("*")
@@ -137,6 +399,8 @@ const (
 	ForUpdate = Raw("FOR UPDATE")
 )
 
+// MaybeForUpdate returns ForUpdate if forUpdate is true, and Empty otherwise.
+// Select.Lock supports other lock strengths, NOWAIT and SKIP LOCKED.
 func MaybeForUpdate(forUpdate bool) Expr {
 	if forUpdate {
 		return ForUpdate
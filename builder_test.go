@@ -38,3 +38,99 @@ func TestBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildWith(t *testing.T) {
+	expr := Select{Fields: List{Star}, From: Table("foos"), Where: Where{Eq(Column("foo"), 42), Eq(Column("bar"), 24)}}
+	tests := []struct {
+		dialect  *Dialect
+		expected string
+	}{
+		{PostgresDialect, "SELECT * FROM foos WHERE foo = $1 AND bar = $2 [42, 24]"},
+		{SQLiteDialect, "SELECT * FROM foos WHERE foo = ? AND bar = ? [42, 24]"},
+		{MySQLDialect, "SELECT * FROM foos WHERE foo = ? AND bar = ? [42, 24]"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.dialect.Name, func(t *testing.T) {
+			t.Parallel()
+			sql, args := BuildWith(test.dialect, expr)
+			if a, e := FormatSQLArgs(sql, args), test.expected; a != e {
+				t.Errorf("got %q, wanted %q", a, e)
+			}
+		})
+	}
+}
+
+func TestReuseArgs(t *testing.T) {
+	query := NewParam("%abc%")
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		reuse    bool
+		expr     Expr
+		expected string
+	}{
+		{"no reuse", PostgresDialect, false, Or{Like(Column("a"), "x"), Like(Column("b"), "x")}, "(a LIKE $1 OR b LIKE $2) [x, x]"},
+		{"reuse", PostgresDialect, true, Or{Like(Column("a"), "x"), Like(Column("b"), "x"), Eq(Column("c"), 1), Eq(Column("d"), "x")}, "(a LIKE $1 OR b LIKE $1 OR c = $2 OR d = $1) [x, 1]"},
+		{"reuse distinguishes types", PostgresDialect, true, Or{Eq(Column("a"), 1), Eq(Column("b"), int64(1))}, "(a = $1 OR b = $2) [1, 1]"},
+		{"reuse skips slices", PostgresDialect, true, Or{Eq(Column("a"), []byte("x")), Eq(Column("b"), []byte("x"))}, "(a = $1 OR b = $2) [[120], [120]]"},
+		{"reuse with @p", SQLServerDialect, true, In(Column("a"), Array{1, 2, 1, 2}), "a IN (@p1, @p2, @p1, @p2) [1, 2]"},
+		{"reuse ignored for ?", SQLiteDialect, true, Or{Eq(Column("a"), 1), Eq(Column("b"), 1)}, "(a = ? OR b = ?) [1, 1]"},
+		{"param", PostgresDialect, false, Or{Like(Column("a"), query), Like(Column("b"), query), Eq(Column("c"), "%abc%")}, "(a LIKE $1 OR b LIKE $1 OR c = $2) [%abc%, %abc%]"},
+		{"param with ?", SQLiteDialect, false, Or{Like(Column("a"), query), Like(Column("b"), query)}, "(a LIKE ? OR b LIKE ?) [%abc%, %abc%]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBuilder(test.dialect)
+			b.SetReuseArgs(test.reuse)
+			b.Append(test.expr)
+			if a, e := b.String(), test.expected; a != e {
+				t.Errorf("got %q, wanted %q", a, e)
+			}
+		})
+	}
+}
+
+func TestBuildErr(t *testing.T) {
+	bad := *PostgresDialect
+	bad.ArgStyle = ArgStyle(100)
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+	}{
+		{"ok", PostgresDialect, Eq(Column("foo"), 1), ""},
+		{"unknown ArgStyle", &bad, Eq(Column("foo"), 1), "sqlexpr: PostgreSQL dialect has unsupported ArgStyle 100"},
+		{"several errors", &bad, And{Eq(Column("foo"), 1), Update{Table: Table("foos")}}, "sqlexpr: PostgreSQL dialect has unsupported ArgStyle 100; sqlexpr: UPDATE without any SET values"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := BuildWithErr(test.dialect, test.expr)
+			if a := errString(err); a != test.expected {
+				t.Errorf("got error %q, wanted %q", a, test.expected)
+			}
+		})
+	}
+}
+
+// checkBuild builds the expression for the dialect and compares the SQL with
+// its arguments (see FormatSQLArgs) and the build error against the expected ones.
+func checkBuild(t *testing.T, d *Dialect, expr Expr, expected string, expectedErr string) {
+	t.Helper()
+	sql, args, err := BuildWithErr(d, expr)
+	if a := FormatSQLArgs(sql, args); a != expected {
+		t.Errorf("got %q, wanted %q", a, expected)
+	}
+	if a := errString(err); a != expectedErr {
+		t.Errorf("got error %q, wanted %q", a, expectedErr)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -2,48 +2,408 @@ package sqlexpr
 
 import (
 	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
 type ArgStyle int
 
 const (
-	QuestionMarkArgs ArgStyle = iota
-	DollarNumberArgs
+	QuestionMarkArgs ArgStyle = iota // ?
+	DollarNumberArgs                 // $1, $2, ...
+	AtPNumberArgs                    // @p1, @p2, ...
+	ColonNumberArgs                  // :1, :2, ...
+
+	// NamedArgs renders every argument as a named placeholder (:p1 or @p1,
+	// depending on Dialect.NamedArgPrefix) and passes it as sql.NamedArg.
+	NamedArgs
+)
+
+// Numbered returns whether placeholders of this style refer to arguments by
+// position, so that a single argument can be used several times.
+func (s ArgStyle) Numbered() bool {
+	return s == DollarNumberArgs || s == AtPNumberArgs || s == ColonNumberArgs || s == NamedArgs
+}
+
+type GroupingSetsStyle int
+
+const (
+	StandardGroupingSets   GroupingSetsStyle = iota // ROLLUP, CUBE and GROUPING SETS
+	WithRollupGroupingSets                          // only GROUP BY ... WITH ROLLUP
+	NoGroupingSets
+)
+
+type LimitStyle int
+
+const (
+	LimitOffsetLimit LimitStyle = iota // LIMIT n OFFSET m
+	OffsetFetchLimit                   // OFFSET m ROWS FETCH NEXT n ROWS ONLY
+)
+
+type UpsertStyle int
+
+const (
+	OnConflictUpsert     UpsertStyle = iota // ON CONFLICT ... DO NOTHING / DO UPDATE
+	OnDuplicateKeyUpsert                    // INSERT IGNORE / ON DUPLICATE KEY UPDATE
+	NoUpsert
+)
+
+type MultiTableStyle int
+
+const (
+	StandardMultiTable MultiTableStyle = iota // UPDATE ... FROM and DELETE ... USING
+	JoinMultiTable                            // UPDATE a JOIN b and DELETE a FROM a JOIN b
+	NoMultiTable
+)
+
+type MergeStyle int
+
+const (
+	StandardMerge  MergeStyle = iota // MERGE INTO ... WHEN MATCHED AND cond THEN ...
+	SemicolonMerge                   // like StandardMerge, but terminated with a semicolon
+	OracleMerge                      // ON (cond), and WHEN conditions go into WHERE after the action
+	NoMerge
+)
+
+type LockStyle int
+
+const (
+	StandardLocks    LockStyle = iota // all lock strengths, OF tables, NOWAIT and SKIP LOCKED
+	UpdateShareLocks                  // only FOR UPDATE and FOR SHARE
+	UpdateLocks                       // only FOR UPDATE, without OF tables
+	IgnoredLocks                      // locks are omitted, e.g. because the whole database is locked
+	NoLocks
 )
 
 type Dialect struct {
 	Name     string
 	ArgStyle ArgStyle
+
+	// NamedArgPrefix starts named placeholders, e.g. ":" or "@". Empty means
+	// the dialect has no named placeholders, and NamedArg falls back to
+	// positional ones.
+	NamedArgPrefix string
+
+	// IdentQuote is the character used to quote identifiers ('"' if zero).
+	IdentQuote byte
+
+	// ReservedWords are lower-case keywords that must be quoted when used as names.
+	ReservedWords map[string]bool
+
+	// TableAliasAs says whether AS is written between a table and its alias.
+	TableAliasAs bool
+
+	// AlwaysQuote makes QuoteName quote every identifier, not just the ones that need it.
+	AlwaysQuote bool
+
+	// NullSafeEqualOp is a NULL-safe equality operator (like MySQL's <=>) used
+	// instead of IS [NOT] DISTINCT FROM. Empty means standard syntax.
+	NullSafeEqualOp string
+
+	GroupingSets GroupingSetsStyle
+
+	LimitStyle LimitStyle
+
+	// NoLimit is the LIMIT value that means no limit, for dialects that don't
+	// allow OFFSET without LIMIT (e.g. -1 in SQLite).
+	NoLimit string
+
+	// OffsetFetchNeedsOrderBy says whether OFFSET ... FETCH requires ORDER BY.
+	OffsetFetchNeedsOrderBy bool
+
+	// NoFetchZero says whether FETCH NEXT 0 ROWS is rejected, so LimitZero
+	// cannot be used.
+	NoFetchZero bool
+
+	UpsertStyle UpsertStyle
+
+	// ConflictConstraint says whether ON CONFLICT ON CONSTRAINT is supported.
+	ConflictConstraint bool
+
+	UpdateFrom  MultiTableStyle
+	DeleteUsing MultiTableStyle
+
+	MergeStyle MergeStyle
+
+	LockStyle LockStyle
+
+	// DefaultValues is what an INSERT without values uses instead of the
+	// standard DEFAULT VALUES, e.g. () VALUES () in MySQL.
+	DefaultValues string
+
+	// NoDefaultValues says whether an INSERT needs at least one value, as
+	// there is no DEFAULT VALUES equivalent (e.g. in Oracle).
+	NoDefaultValues bool
+
+	// MaxArgs is the maximum number of arguments in a single statement, or
+	// zero if there is no limit. Note that SQLite before 3.32 only allows 999.
+	MaxArgs int
+
+	// DistinctOn says whether SELECT DISTINCT ON (...) is supported.
+	DistinctOn bool
+
+	// FullWindowFrames says whether window frames support GROUPS and EXCLUDE.
+	FullWindowFrames bool
+
+	// InsertWithBeforeSource says whether the WITH clause of an INSERT goes
+	// right before its source query instead of before INSERT, as in MySQL and
+	// Oracle.
+	InsertWithBeforeSource bool
+
+	// PlainRecursiveWith says whether recursive CTEs use plain WITH, without
+	// the RECURSIVE keyword.
+	PlainRecursiveWith bool
+
+	// CTEMaterialization says whether CTEs can be [NOT] MATERIALIZED.
+	CTEMaterialization bool
+
+	// CompoundParens says whether members of UNION and other set operations
+	// can be parenthesized.
+	CompoundParens bool
+
+	// RowValues says whether row values can be compared, as in (a, b) > (1, 2).
+	RowValues bool
+
+	// BoolLiterals says whether TRUE and FALSE literals are supported (1 and 0 are used otherwise).
+	BoolLiterals bool
+
+	// BackslashEscapes says whether backslashes in string literals are escape characters.
+	BackslashEscapes bool
+
+	// BytesLiteral is a fmt format of []byte literals, applied to hex-encoded bytes.
+	BytesLiteral string
+
+	// TimeLayout is the layout of time.Time literals.
+	TimeLayout string
 }
 
+// FormatPlaceholder returns the placeholder for the argument with the given
+// zero-based index, or an empty string if the ArgStyle is unknown (or is
+// NamedArgs without a NamedArgPrefix).
 func (d *Dialect) FormatPlaceholder(index int) string {
 	switch d.ArgStyle {
 	case QuestionMarkArgs:
 		return "?"
 	case DollarNumberArgs:
 		return "$" + strconv.Itoa(index+1)
+	case AtPNumberArgs:
+		return "@p" + strconv.Itoa(index+1)
+	case ColonNumberArgs:
+		return ":" + strconv.Itoa(index+1)
+	case NamedArgs:
+		return d.FormatNamedPlaceholder(autoArgName(index))
 	default:
-		panic("unknown ArgStyle")
+		return ""
+	}
+}
+
+// FormatNamedPlaceholder returns the placeholder for the given named argument,
+// or an empty string if the dialect does not support named arguments.
+func (d *Dialect) FormatNamedPlaceholder(name string) string {
+	if d.NamedArgPrefix == "" {
+		return ""
+	}
+	return d.NamedArgPrefix + name
+}
+
+func autoArgName(index int) string {
+	return "p" + strconv.Itoa(index+1)
+}
+
+// QuoteName quotes each dot-separated part of the given name if it is a
+// reserved word, mixes upper- and lower-case letters, contains characters
+// other than letters, digits and underscores, or if AlwaysQuote is set. Parts
+// that are already quoted and * are left alone.
+func (d *Dialect) QuoteName(name string) string {
+	if !strings.Contains(name, ".") {
+		return d.quoteNamePart(name)
+	}
+	parts := d.splitName(name)
+	for i, part := range parts {
+		parts[i] = d.quoteNamePart(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitName splits the name on dots that are not inside quotes.
+func (d *Dialect) splitName(name string) []string {
+	q := d.identQuote()
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case q:
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				parts = append(parts, name[start:i])
+				start = i + 1
+			}
+		}
 	}
+	return append(parts, name[start:])
+}
+
+func (d *Dialect) quoteNamePart(name string) string {
+	q := d.identQuote()
+	if name == "*" || (len(name) >= 2 && name[0] == q && name[len(name)-1] == q) {
+		return name
+	}
+	if !d.AlwaysQuote && !d.needsQuoting(name) {
+		return name
+	}
+	qs := string(q)
+	return qs + strings.Replace(name, qs, qs+qs, -1) + qs
+}
+
+func (d *Dialect) needsQuoting(name string) bool {
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		return true
+	}
+	var lower, upper bool
+	for _, r := range name {
+		switch {
+		case 'a' <= r && r <= 'z':
+			lower = true
+		case 'A' <= r && r <= 'Z':
+			upper = true
+		case '0' <= r && r <= '9' || r == '_':
+		default:
+			return true
+		}
+	}
+	return (lower && upper) || d.ReservedWords[strings.ToLower(name)]
+}
+
+func (d *Dialect) identQuote() byte {
+	if d.IdentQuote == 0 {
+		return '"'
+	}
+	return d.IdentQuote
 }
 
 var PostgresDialect = &Dialect{
-	Name:     "PostgreSQL",
-	ArgStyle: DollarNumberArgs,
+	Name:               "PostgreSQL",
+	ArgStyle:           DollarNumberArgs,
+	IdentQuote:         '"',
+	ReservedWords:      reservedWords(commonReservedWords, postgresReservedWords),
+	TableAliasAs:       true,
+	CTEMaterialization: true,
+	DistinctOn:         true,
+	ConflictConstraint: true,
+	FullWindowFrames:   true,
+	RowValues:          true,
+	BoolLiterals:       true,
+	BytesLiteral:       `'\x%s'::bytea`,
+	TimeLayout:         "2006-01-02 15:04:05.999999999Z07:00",
+	CompoundParens:     true,
+	MaxArgs:            65535,
 }
 
 var SQLiteDialect = &Dialect{
-	Name:     "SQLite",
-	ArgStyle: QuestionMarkArgs,
+	Name:           "SQLite",
+	ArgStyle:       QuestionMarkArgs,
+	NamedArgPrefix: ":",
+	IdentQuote:     '"',
+	ReservedWords:  reservedWords(commonReservedWords, sqliteReservedWords),
+	TableAliasAs:   true,
+	GroupingSets:   NoGroupingSets,
+	NoLimit:        "-1",
+	RowValues:      true,
+	BoolLiterals:   true,
+	BytesLiteral:   "X'%s'",
+	TimeLayout:     "2006-01-02 15:04:05.999999999-07:00",
+	MaxArgs:        32766,
+	DeleteUsing:    NoMultiTable,
+	MergeStyle:     NoMerge,
+	LockStyle:      IgnoredLocks,
+
+	FullWindowFrames: true,
 }
 
 var MySQLDialect = &Dialect{
-	Name:     "MySQL",
-	ArgStyle: QuestionMarkArgs,
+	Name:             "MySQL",
+	ArgStyle:         QuestionMarkArgs,
+	IdentQuote:       '`',
+	ReservedWords:    reservedWords(commonReservedWords, mysqlReservedWords),
+	TableAliasAs:     true,
+	RowValues:        true,
+	BoolLiterals:     true,
+	NullSafeEqualOp:  "<=>",
+	GroupingSets:     WithRollupGroupingSets,
+	NoLimit:          "18446744073709551615",
+	BackslashEscapes: true,
+	BytesLiteral:     "X'%s'",
+	TimeLayout:       "2006-01-02 15:04:05.999999",
+	CompoundParens:   true,
+	MaxArgs:          65535,
+	DefaultValues:    "() VALUES ()",
+	UpsertStyle:      OnDuplicateKeyUpsert,
+	UpdateFrom:       JoinMultiTable,
+	DeleteUsing:      JoinMultiTable,
+	MergeStyle:       NoMerge,
+	LockStyle:        UpdateShareLocks,
+
+	InsertWithBeforeSource: true,
+}
+
+var SQLServerDialect = &Dialect{
+	Name:           "SQL Server",
+	ArgStyle:       AtPNumberArgs,
+	NamedArgPrefix: "@",
+	IdentQuote:     '"',
+	ReservedWords:  reservedWords(commonReservedWords, sqlServerReservedWords),
+	TableAliasAs:   true,
+	LimitStyle:     OffsetFetchLimit,
+	BytesLiteral:   "0x%s",
+	TimeLayout:     "2006-01-02T15:04:05.9999999Z07:00",
+	MaxArgs:        2100,
+	UpsertStyle:    NoUpsert,
+	DeleteUsing:    NoMultiTable,
+	MergeStyle:     SemicolonMerge,
+	LockStyle:      NoLocks,
+
+	OffsetFetchNeedsOrderBy: true,
+	NoFetchZero:             true,
+	PlainRecursiveWith:      true,
+	CompoundParens:          true,
 }
 
-var dialect *Dialect = PostgresDialect
+var OracleDialect = &Dialect{
+	Name:           "Oracle",
+	ArgStyle:       ColonNumberArgs,
+	NamedArgPrefix: ":",
+	IdentQuote:     '"',
+	ReservedWords:  reservedWords(commonReservedWords, oracleReservedWords),
+	LimitStyle:     OffsetFetchLimit,
+	BytesLiteral:   "HEXTORAW('%s')",
+	TimeLayout:     "2006-01-02 15:04:05.999999999Z07:00",
+	CompoundParens: true,
+	UpsertStyle:    NoUpsert,
+	UpdateFrom:     NoMultiTable,
+	DeleteUsing:    NoMultiTable,
+	MergeStyle:     OracleMerge,
+	LockStyle:      UpdateLocks,
+
+	FullWindowFrames:       true,
+	PlainRecursiveWith:     true,
+	InsertWithBeforeSource: true,
+	NoDefaultValues:        true,
+}
 
+var dialect atomic.Value
+
+func init() {
+	dialect.Store(PostgresDialect)
+}
+
+// SetDialect changes the default dialect used by Build, Exec, Query and by
+// Builders that don't have a dialect of their own. Use NewBuilder or BuildWith
+// to render SQL for several databases at once.
 func SetDialect(d *Dialect) {
-	dialect = d
+	dialect.Store(d)
+}
+
+func defaultDialect() *Dialect {
+	return dialect.Load().(*Dialect)
 }
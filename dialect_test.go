@@ -36,3 +36,75 @@ func TestMySQLDialect(t *testing.T) {
 		}
 	})
 }
+
+func TestQuoteName(t *testing.T) {
+	alwaysQuote := *PostgresDialect
+	alwaysQuote.AlwaysQuote = true
+
+	tests := []struct {
+		dialect  *Dialect
+		name     string
+		expected string
+	}{
+		{PostgresDialect, "foo", "foo"},
+		{PostgresDialect, "foo_bar2", "foo_bar2"},
+		{PostgresDialect, "order", `"order"`},
+		{PostgresDialect, "user", `"user"`},
+		{PostgresDialect, "userId", `"userId"`},
+		{PostgresDialect, "USERS", "USERS"},
+		{PostgresDialect, "ORDER", `"ORDER"`},
+		{PostgresDialect, "foo bar", `"foo bar"`},
+		{PostgresDialect, "2fa", `"2fa"`},
+		{PostgresDialect, `a"b`, `"a""b"`},
+		{PostgresDialect, "accounts.id", "accounts.id"},
+		{PostgresDialect, "public.order", `public."order"`},
+		{PostgresDialect, "foos.*", "foos.*"},
+		{PostgresDialect, `"Foo"`, `"Foo"`},
+		{PostgresDialect, `"a.b"`, `"a.b"`},
+		{PostgresDialect, `"a.b".Order`, `"a.b"."Order"`},
+		{MySQLDialect, "`a.b`.c", "`a.b`.c"},
+		{SQLiteDialect, "order", `"order"`},
+		{SQLiteDialect, "pragma", `"pragma"`},
+		{MySQLDialect, "order", "`order`"},
+		{MySQLDialect, "key", "`key`"},
+		{MySQLDialect, "a`b", "`a``b`"},
+		{&alwaysQuote, "foo", `"foo"`},
+		{&alwaysQuote, "foos.id", `"foos"."id"`},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name+" "+test.name, func(t *testing.T) {
+			if a, e := test.dialect.QuoteName(test.name), test.expected; a != e {
+				t.Errorf("got %q, wanted %q", a, e)
+			}
+		})
+	}
+}
+
+func TestSQLServerDialect(t *testing.T) {
+	d := SQLServerDialect
+	t.Run("FormatPlaceholder", func(t *testing.T) {
+		a := d.FormatPlaceholder(0) + " " + d.FormatPlaceholder(1)
+		e := "@p1 @p2"
+		if a != e {
+			t.Errorf("got %q, wanted %q", a, e)
+		}
+	})
+}
+
+func TestOracleDialect(t *testing.T) {
+	d := OracleDialect
+	t.Run("FormatPlaceholder", func(t *testing.T) {
+		a := d.FormatPlaceholder(0) + " " + d.FormatPlaceholder(1)
+		e := ":1 :2"
+		if a != e {
+			t.Errorf("got %q, wanted %q", a, e)
+		}
+	})
+	t.Run("TableAlias", func(t *testing.T) {
+		a, _ := BuildWith(d, Subquery(Select{Fields: List{Column("x")}, From: Table("foo").As("f")}, "s"))
+		e := "(SELECT x FROM foo f) s"
+		if a != e {
+			t.Errorf("got %q, wanted %q", a, e)
+		}
+	})
+}
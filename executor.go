@@ -3,6 +3,7 @@ package sqlexpr
 import (
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 // Executor is compatible with *sql.DB, *sql.Tx (or an arbitrary middleman)
@@ -12,16 +13,28 @@ type Executor interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+// Exec builds the statement and executes it, returning build errors (see
+// Builder.Err) without contacting the database.
 func Exec(ctx context.Context, ex Executor, expr Expr) (sql.Result, error) {
-	query, args := Build(expr)
+	query, args, err := BuildErr(expr)
+	if err != nil {
+		return nil, err
+	}
 	return ex.ExecContext(ctx, query, args...)
 }
 
+// Query builds the statement and runs it, returning build errors (see
+// Builder.Err) without contacting the database.
 func Query(ctx context.Context, ex Executor, expr Expr) (*sql.Rows, error) {
-	query, args := Build(expr)
+	query, args, err := BuildErr(expr)
+	if err != nil {
+		return nil, err
+	}
 	return ex.QueryContext(ctx, query, args...)
 }
 
+// QueryRow builds the statement and runs it. *sql.Row cannot carry build
+// errors, so use Query or BuildErr when the statement may be invalid.
 func QueryRow(ctx context.Context, ex Executor, expr Expr) *sql.Row {
 	query, args := Build(expr)
 	return ex.QueryRowContext(ctx, query, args...)
@@ -35,11 +48,52 @@ func (s *Select) QueryRow(ctx context.Context, ex Executor) *sql.Row {
 	return QueryRow(ctx, ex, s)
 }
 
+func (s *Compound) Query(ctx context.Context, ex Executor) (*sql.Rows, error) {
+	return Query(ctx, ex, s)
+}
+
+func (s *Compound) QueryRow(ctx context.Context, ex Executor) *sql.Row {
+	return QueryRow(ctx, ex, s)
+}
+
+// Exec executes the insert, splitting it into several statements if it has
+// more arguments than the default dialect allows (see Insert.Batches). The
+// result reports the total number of affected rows and the last insert ID of
+// the last statement. If a statement fails, the result of the statements
+// that succeeded is returned along with the error (or nil if none did); run
+// large inserts in a *sql.Tx to make them all-or-nothing.
 func (s *Insert) Exec(ctx context.Context, ex Executor) (sql.Result, error) {
-	return Exec(ctx, ex, s)
+	batches, err := s.Batches(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 1 {
+		return Exec(ctx, ex, batches[0])
+	}
+	var results batchResult
+	for _, batch := range batches {
+		result, err := Exec(ctx, ex, batch)
+		if err != nil {
+			if len(results) == 0 {
+				return nil, err
+			}
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
 }
 
+// Query runs the insert, which must fit into a single statement (see
+// Insert.Batches).
 func (s *Insert) Query(ctx context.Context, ex Executor) (*sql.Rows, error) {
+	batches, err := s.Batches(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) > 1 {
+		return nil, fmt.Errorf("sqlexpr: INSERT needs %d statements, which Query cannot combine", len(batches))
+	}
 	return Query(ctx, ex, s)
 }
 
@@ -70,3 +124,25 @@ func (s *Delete) Query(ctx context.Context, ex Executor) (*sql.Rows, error) {
 func (s *Delete) QueryRow(ctx context.Context, ex Executor) *sql.Row {
 	return QueryRow(ctx, ex, s)
 }
+
+func (s *Merge) Exec(ctx context.Context, ex Executor) (sql.Result, error) {
+	return Exec(ctx, ex, s)
+}
+
+type batchResult []sql.Result
+
+func (r batchResult) LastInsertId() (int64, error) {
+	return r[len(r)-1].LastInsertId()
+}
+
+func (r batchResult) RowsAffected() (int64, error) {
+	var total int64
+	for _, result := range r {
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
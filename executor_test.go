@@ -0,0 +1,80 @@
+package sqlexpr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type fakeExecutor struct {
+	queries []string
+	failAt  int
+}
+
+func (ex *fakeExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ex.queries = append(ex.queries, FormatSQLArgs(query, args))
+	if len(ex.queries) == ex.failAt {
+		return nil, errors.New("failed")
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (ex *fakeExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ex.queries = append(ex.queries, FormatSQLArgs(query, args))
+	return nil, nil
+}
+
+func (ex *fakeExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ex.queries = append(ex.queries, FormatSQLArgs(query, args))
+	return nil
+}
+
+func TestExecBuildError(t *testing.T) {
+	ex := new(fakeExecutor)
+	if _, err := (&Update{Table: Table("foos")}).Exec(context.Background(), ex); err == nil {
+		t.Errorf("got no error")
+	}
+	if _, err := Query(context.Background(), ex, Insert{Table: Table("foos"), Columns: []Expr{Column("a")}, Rows: [][]interface{}{{}}}); err == nil {
+		t.Errorf("got no error")
+	}
+	if _, err := (&Merge{Table: Table("foos"), Using: Table("bars"), On: []Expr{TRUE}}).Exec(context.Background(), ex); err == nil {
+		t.Errorf("got no error")
+	}
+	if len(ex.queries) != 0 {
+		t.Errorf("executed %q", ex.queries)
+	}
+}
+
+func TestInsertExecBatches(t *testing.T) {
+	d := *PostgresDialect
+	d.MaxArgs = 4
+	SetDialect(&d)
+	defer SetDialect(PostgresDialect)
+
+	s := Insert{Table: Table("foos"), Columns: []Expr{Column("a"), Column("b")}, Rows: [][]interface{}{{1, 2}, {3, 4}, {5, 6}}}
+	ex := new(fakeExecutor)
+	result, err := s.Exec(context.Background(), ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := result.RowsAffected(); n != 6 {
+		t.Errorf("RowsAffected = %d, wanted 6", n)
+	}
+	if len(ex.queries) != 2 {
+		t.Fatalf("executed %q", ex.queries)
+	}
+	if _, err := s.Query(context.Background(), ex); err == nil {
+		t.Errorf("Query got no error")
+	}
+
+	ex = &fakeExecutor{failAt: 2}
+	result, err = s.Exec(context.Background(), ex)
+	if err == nil {
+		t.Fatalf("got no error")
+	}
+	if n, _ := result.RowsAffected(); n != 4 {
+		t.Errorf("partial RowsAffected = %d, wanted 4", n)
+	}
+}
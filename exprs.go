@@ -1,5 +1,10 @@
 package sqlexpr
 
+import (
+	"fmt"
+	"reflect"
+)
+
 type value struct {
 	v interface{}
 }
@@ -16,6 +21,48 @@ func Value(v interface{}) Expr {
 	}
 }
 
+type namedArg struct {
+	name string
+	v    interface{}
+}
+
+// NamedArg renders a named placeholder like :name or @name and passes the
+// value as sql.NamedArg. Using the same name several times passes the value
+// once, and using it with different values is a build error. Dialects without
+// named placeholders get a positional one instead. Note that the NamedArgs
+// style names other arguments p1, p2 and so on.
+func NamedArg(name string, v interface{}) Expr {
+	return namedArg{name, v}
+}
+
+func (v namedArg) AppendToSQLBuilder(b *Builder) {
+	placeholder := b.Dialect().FormatNamedPlaceholder(v.name)
+	if placeholder == "" || b.inlineArgs {
+		b.Append(v.v)
+		return
+	}
+	if prev, ok := b.namedArgs[v.name]; !ok {
+		b.addNamedArg(v.name, v.v)
+	} else if !reflect.DeepEqual(prev, v.v) {
+		b.AddError(fmt.Errorf("sqlexpr: named argument %s is used with different values", v.name))
+	}
+	b.AppendRaw(placeholder)
+}
+
+// Param is a value that is bound to a single placeholder however many times
+// it is used, provided the dialect numbers its placeholders.
+type Param struct {
+	Value interface{}
+}
+
+func NewParam(v interface{}) *Param {
+	return &Param{v}
+}
+
+func (p *Param) AppendToSQLBuilder(b *Builder) {
+	b.appendArg(p.Value, p)
+}
+
 type Fragment []interface{}
 
 func (v Fragment) AppendToSQLBuilder(b *Builder) {
@@ -30,6 +77,65 @@ func Eq(lhs interface{}, rhs interface{}) Expr {
 	return Op(lhs, "=", rhs)
 }
 
+func NotEq(lhs interface{}, rhs interface{}) Expr {
+	return Op(lhs, "<>", rhs)
+}
+
+func Lt(lhs interface{}, rhs interface{}) Expr {
+	return Op(lhs, "<", rhs)
+}
+
+func Lte(lhs interface{}, rhs interface{}) Expr {
+	return Op(lhs, "<=", rhs)
+}
+
+func Gt(lhs interface{}, rhs interface{}) Expr {
+	return Op(lhs, ">", rhs)
+}
+
+func Gte(lhs interface{}, rhs interface{}) Expr {
+	return Op(lhs, ">=", rhs)
+}
+
+func Between(v interface{}, lower interface{}, upper interface{}) Expr {
+	return Fragment{v, Raw("BETWEEN"), lower, Raw("AND"), upper}
+}
+
+func NotBetween(v interface{}, lower interface{}, upper interface{}) Expr {
+	return Fragment{v, Raw("NOT BETWEEN"), lower, Raw("AND"), upper}
+}
+
+type distinctFrom struct {
+	lhs, rhs interface{}
+	distinct bool
+}
+
+// IsDistinctFrom is a NULL-safe <>, rendered as NOT (a <=> b) on dialects
+// that have a NULL-safe equality operator instead (see Dialect.NullSafeEqualOp).
+func IsDistinctFrom(lhs interface{}, rhs interface{}) Expr {
+	return distinctFrom{lhs, rhs, true}
+}
+
+// IsNotDistinctFrom is a NULL-safe =, rendered as a <=> b on dialects that
+// have a NULL-safe equality operator instead (see Dialect.NullSafeEqualOp).
+func IsNotDistinctFrom(lhs interface{}, rhs interface{}) Expr {
+	return distinctFrom{lhs, rhs, false}
+}
+
+func (v distinctFrom) AppendToSQLBuilder(b *Builder) {
+	if op := b.Dialect().NullSafeEqualOp; op != "" {
+		if v.distinct {
+			Not(Parens(Op(v.lhs, op, v.rhs))).AppendToSQLBuilder(b)
+		} else {
+			Op(v.lhs, op, v.rhs).AppendToSQLBuilder(b)
+		}
+	} else if v.distinct {
+		Op(v.lhs, "IS DISTINCT FROM", v.rhs).AppendToSQLBuilder(b)
+	} else {
+		Op(v.lhs, "IS NOT DISTINCT FROM", v.rhs).AppendToSQLBuilder(b)
+	}
+}
+
 func Like(lhs interface{}, rhs interface{}) Expr {
 	return Op(lhs, "LIKE", rhs)
 }
@@ -114,7 +220,12 @@ func ArrayOfStrings(items []string) Array {
 	return arr
 }
 
+// In renders lhs IN items, where items is an Array or a subquery. An empty
+// Array yields FALSE, and a single-item one yields an equality.
 func In(lhs interface{}, items Expr) Expr {
+	if isStatement(items) {
+		return InSubquery(lhs, items)
+	}
 	if a, ok := items.(arrayLike); ok {
 		switch a.Count() {
 		case 0:
@@ -126,6 +237,55 @@ func In(lhs interface{}, items Expr) Expr {
 	return Fragment{lhs, Raw("IN"), items}
 }
 
+// NotIn is the opposite of In. An empty Array yields TRUE, and a single-item
+// one yields <>.
+func NotIn(lhs interface{}, items Expr) Expr {
+	if isStatement(items) {
+		return NotInSubquery(lhs, items)
+	}
+	if a, ok := items.(arrayLike); ok {
+		switch a.Count() {
+		case 0:
+			return TRUE
+		case 1:
+			return NotEq(lhs, a.At(0))
+		}
+	}
+	return Fragment{lhs, Raw("NOT IN"), items}
+}
+
+func Exists(stmt Expr) Expr {
+	return Fragment{Raw("EXISTS"), Parens(stmt)}
+}
+
+func NotExists(stmt Expr) Expr {
+	return Fragment{Raw("NOT EXISTS"), Parens(stmt)}
+}
+
+func InSubquery(lhs interface{}, stmt Expr) Expr {
+	return Fragment{lhs, Raw("IN"), Parens(stmt)}
+}
+
+func NotInSubquery(lhs interface{}, stmt Expr) Expr {
+	return Fragment{lhs, Raw("NOT IN"), Parens(stmt)}
+}
+
+// Any renders lhs op ANY (stmt), e.g. Any(price, ">", sel).
+func Any(lhs interface{}, op string, stmt Expr) Expr {
+	return Fragment{lhs, Raw(op), Raw("ANY"), Parens(stmt)}
+}
+
+// All renders lhs op ALL (stmt), e.g. All(price, ">", sel).
+func All(lhs interface{}, op string, stmt Expr) Expr {
+	return Fragment{lhs, Raw(op), Raw("ALL"), Parens(stmt)}
+}
+
+// Subquery parenthesizes the statement for use in FROM, joins or as a scalar
+// value, adding AS alias unless the alias is empty.
+func Subquery(stmt Expr, alias Table) TableAlias {
+	return TableAlias{Parens(stmt), alias}
+}
+
 type And []interface{}
 
 func (v And) AppendToSQLBuilder(b *Builder) {
@@ -136,12 +296,16 @@ func (v And) AppendToSQLBuilder(b *Builder) {
 		b.Append(v[0])
 	default:
 		b.AppendRaw("(")
+		b.Indent()
 		for i, item := range v {
+			b.NewLine()
 			if i > 0 {
-				b.AppendRaw(" AND ")
+				b.AppendRaw("AND")
 			}
 			b.Append(item)
 		}
+		b.Unindent()
+		b.NewLine()
 		b.AppendRaw(")")
 	}
 }
@@ -156,26 +320,95 @@ func (v Or) AppendToSQLBuilder(b *Builder) {
 		b.Append(v[0])
 	default:
 		b.AppendRaw("(")
+		b.Indent()
 		for i, item := range v {
+			b.NewLine()
 			if i > 0 {
-				b.AppendRaw(" OR ")
+				b.AppendRaw("OR")
 			}
 			b.Append(item)
 		}
+		b.Unindent()
+		b.NewLine()
 		b.AppendRaw(")")
 	}
 }
 
+type When struct {
+	Cond   interface{}
+	Result interface{}
+}
+
+// Case is a CASE expression. If Value is nil, it is a searched CASE with
+// boolean conditions; otherwise it compares Value to each When.Cond. Without
+// any Whens, it renders just the Else value (or NULL).
+type Case struct {
+	Value interface{}
+	Whens []When
+	Else  interface{}
+}
+
+func (c *Case) AddWhen(cond interface{}, result interface{}) {
+	c.Whens = append(c.Whens, When{cond, result})
+}
+
+func (c Case) AppendToSQLBuilder(b *Builder) {
+	if len(c.Whens) == 0 {
+		if c.Else == nil {
+			b.AppendRaw("NULL")
+		} else {
+			b.Append(c.Else)
+		}
+		return
+	}
+	b.AppendRaw("CASE")
+	if c.Value != nil {
+		b.Append(c.Value)
+	}
+	b.Indent()
+	for _, w := range c.Whens {
+		b.NewLine()
+		b.AppendRaw("WHEN")
+		b.Append(w.Cond)
+		b.AppendRaw("THEN")
+		b.Append(w.Result)
+	}
+	if c.Else != nil {
+		b.NewLine()
+		b.AppendRaw("ELSE")
+		b.Append(c.Else)
+	}
+	b.Unindent()
+	b.NewLine()
+	b.AppendRaw("END")
+}
+
 func Not(v interface{}) Expr {
 	return Fragment{Raw("NOT"), v}
 }
 
+// Ordering is an ORDER BY item with an explicit direction, as returned by Asc
+// and Desc.
+type Ordering struct {
+	Expr Expr
+	Desc bool
+}
+
+func (v Ordering) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(v.Expr)
+	if v.Desc {
+		b.AppendRaw("DESC")
+	} else {
+		b.AppendRaw("ASC")
+	}
+}
+
 func Asc(v Expr) Expr {
-	return Fragment{v, Raw("ASC")}
+	return Ordering{v, false}
 }
 
 func Desc(v Expr) Expr {
-	return Fragment{v, Raw("DESC")}
+	return Ordering{v, true}
 }
 
 func Dot(a, b Expr) Expr {
@@ -228,6 +461,10 @@ type parenthesized struct {
 }
 
 func (v parenthesized) AppendToSQLBuilder(b *Builder) {
+	if isStatement(v.v) {
+		b.AppendSubquery(v.v)
+		return
+	}
 	b.AppendRaw("(")
 	v.v.AppendToSQLBuilder(b)
 	b.AppendRaw(")")
@@ -14,6 +14,16 @@ func TestExprs(t *testing.T) {
 		{"column value eq", Eq(Column("foo"), 42), "foo = $1 [42]"},
 		{"less than op", Op(Column("foo"), "<", 42), "foo < $1 [42]"},
 
+		{"NotEq", NotEq(Column("foo"), 42), "foo <> $1 [42]"},
+		{"Lt", Lt(Column("foo"), 42), "foo < $1 [42]"},
+		{"Lte", Lte(Column("foo"), 42), "foo <= $1 [42]"},
+		{"Gt", Gt(Column("foo"), 42), "foo > $1 [42]"},
+		{"Gte", Gte(Column("foo"), 42), "foo >= $1 [42]"},
+		{"Between", Between(Column("foo"), 1, 10), "foo BETWEEN $1 AND $2 [1, 10]"},
+		{"NotBetween", NotBetween(Column("foo"), 1, 10), "foo NOT BETWEEN $1 AND $2 [1, 10]"},
+		{"IsDistinctFrom", IsDistinctFrom(Column("foo"), 42), "foo IS DISTINCT FROM $1 [42]"},
+		{"IsNotDistinctFrom", IsNotDistinctFrom(Column("foo"), Column("bar")), "foo IS NOT DISTINCT FROM bar"},
+
 		{"IsNull", IsNull(Column("foo")), "foo IS NULL"},
 		{"IsNotNull", IsNotNull(Column("foo")), "foo IS NOT NULL"},
 
@@ -23,6 +33,10 @@ func TestExprs(t *testing.T) {
 		{"In single-element array", In(Column("foo"), Array{42}), "foo = $1 [42]"},
 		{"In array", In(Column("foo"), Array{10, 20, 30}), "foo IN ($1, $2, $3) [10, 20, 30]"},
 
+		{"NotIn empty array", NotIn(Column("foo"), Array{}), "TRUE"},
+		{"NotIn single-element array", NotIn(Column("foo"), Array{42}), "foo <> $1 [42]"},
+		{"NotIn array", NotIn(Column("foo"), Array{10, 20, 30}), "foo NOT IN ($1, $2, $3) [10, 20, 30]"},
+
 		{"ArrayOfInt64s", ArrayOfInt64s([]int64{10, 20, 30}), "($1, $2, $3) [10, 20, 30]"},
 		{"ArrayOfInts", ArrayOfInts([]int{10, 20, 30}), "($1, $2, $3) [10, 20, 30]"},
 		{"ArrayOfStrings", ArrayOfStrings([]string{"foo", "bar", "boz"}), "($1, $2, $3) [foo, bar, boz]"},
@@ -37,6 +51,13 @@ func TestExprs(t *testing.T) {
 
 		{"Not", Not(Column("foo")), "NOT foo"},
 
+		{"Case searched", Case{Whens: []When{{Gt(Column("foo"), 10), "big"}, {Gt(Column("foo"), 0), "small"}}, Else: "none"}, "CASE WHEN foo > $1 THEN $2 WHEN foo > $3 THEN $4 ELSE $5 END [10, big, 0, small, none]"},
+		{"Case simple", Case{Value: Column("kind"), Whens: []When{{"a", Column("x")}, {"b", Column("y")}}}, "CASE kind WHEN $1 THEN x WHEN $2 THEN y END [a, b]"},
+		{"Case without whens", Case{Value: Column("kind"), Else: Column("x")}, "x"},
+		{"Case without whens or else", Case{}, "NULL"},
+		{"Case in Setter", Update{Table: Table("foos"), Setters: []Setter{{Column("size"), Case{Whens: []When{{Gt(Column("n"), 10), "big"}}, Else: "small"}}}}, "UPDATE foos SET size = CASE WHEN n > $1 THEN $2 ELSE $3 END [10, big, small]"},
+		{"Case in OrderBy", Select{Fields: List{Star}, From: Table("foos"), OrderBy: OrderBy{Desc(Case{Whens: []When{{IsNull(Column("x")), 0}}, Else: 1})}}, "SELECT * FROM foos ORDER BY CASE WHEN x IS NULL THEN $1 ELSE $2 END DESC [0, 1]"},
+
 		{"Dot", Dot(Table("foo"), Column("bar")), "foo.bar"},
 
 		{"Func0", Func("foo"), "foo ()"},
@@ -52,6 +73,21 @@ func TestExprs(t *testing.T) {
 
 		{"Parens(func)", Parens(NOW), "(NOW())"},
 		{"Parens(value)", Parens(Value(123)), "($1) [123]"},
+		{"Exists", Exists(Select{Fields: List{Raw("1")}, From: Table("foo"), Where: Where{Eq(Column("x"), 1)}}), "EXISTS (SELECT 1 FROM foo WHERE x = $1) [1]"},
+		{"NotExists", NotExists(Select{Fields: List{Raw("1")}, From: Table("foo")}), "NOT EXISTS (SELECT 1 FROM foo)"},
+		{"InSubquery", InSubquery(Column("id"), Select{Fields: List{Column("foo_id")}, From: Table("bar")}), "id IN (SELECT foo_id FROM bar)"},
+		{"NotInSubquery", NotInSubquery(Column("id"), Select{Fields: List{Column("foo_id")}, From: Table("bar")}), "id NOT IN (SELECT foo_id FROM bar)"},
+		{"In subquery", In(Column("id"), &Select{Fields: List{Column("foo_id")}, From: Table("bar")}), "id IN (SELECT foo_id FROM bar)"},
+		{"NotIn subquery", NotIn(Column("id"), Select{Fields: List{Column("foo_id")}, From: Table("bar")}), "id NOT IN (SELECT foo_id FROM bar)"},
+		{"Any", Any(Column("price"), ">", Select{Fields: List{Column("price")}, From: Table("bar")}), "price > ANY (SELECT price FROM bar)"},
+		{"All", All(Column("price"), ">=", Select{Fields: List{Column("price")}, From: Table("bar")}), "price >= ALL (SELECT price FROM bar)"},
+		{"Subquery", Subquery(Select{Fields: List{Column("x")}, From: Table("foo")}, "f"), "(SELECT x FROM foo) AS f"},
+		{"Subquery scalar", Subquery(Select{Fields: List{Max(Column("x"))}, From: Table("foo")}, ""), "(SELECT MAX (x) FROM foo)"},
+		{"Subquery numbering", Select{
+			Fields: List{Column("a"), As(Subquery(Select{Fields: List{Count(Star)}, From: Table("bar"), Where: Where{Eq(Column("y"), 2)}}, ""), "n")},
+			From:   Subquery(Select{Fields: List{Star}, From: Table("foo"), Where: Where{Eq(Column("x"), 1)}}, "f"),
+			Where:  Where{Exists(Select{Fields: List{Raw("1")}, From: Table("boz"), Where: Where{Eq(Column("z"), 3)}}), Eq(Column("a"), 4)},
+		}, "SELECT a, (SELECT COUNT (*) FROM bar WHERE y = $1) AS n FROM (SELECT * FROM foo WHERE x = $2) AS f WHERE EXISTS (SELECT 1 FROM boz WHERE z = $3) AND a = $4 [2, 1, 3, 4]"},
 		{"Parens(subquery)", Parens(Select{From: Table("foo"), Fields: List{Column("x")}}), "(SELECT x FROM foo)"},
 	}
 
@@ -65,3 +101,63 @@ func TestExprs(t *testing.T) {
 		})
 	}
 }
+
+func TestNamedArg(t *testing.T) {
+	namedSQLite := *SQLiteDialect
+	namedSQLite.ArgStyle = NamedArgs
+
+	expr := And{Eq(Column("foo"), NamedArg("foo", 42)), Eq(Column("bar"), "x"), Eq(Column("boz"), NamedArg("foo", 42))}
+	tests := []struct {
+		dialect  *Dialect
+		expected string
+	}{
+		{SQLiteDialect, "(foo = :foo AND bar = ? AND boz = :foo) [foo=42, x]"},
+		{SQLServerDialect, "(foo = @foo AND bar = @p2 AND boz = @foo) [foo=42, x]"},
+		{PostgresDialect, "(foo = $1 AND bar = $2 AND boz = $3) [42, x, 42]"},
+		{&namedSQLite, "(foo = :foo AND bar = :p2 AND boz = :foo) [foo=42, p2=x]"},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name, func(t *testing.T) {
+			sql, args := BuildWith(test.dialect, expr)
+			if a, e := FormatSQLArgs(sql, args), test.expected; a != e {
+				t.Errorf("got %q, wanted %q", a, e)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name string
+		expr Expr
+		err  string
+	}{
+		{"auto name collision", And{Eq(Column("a"), NamedArg("p2", 1)), Eq(Column("b"), 5)}, "sqlexpr: named argument p2 collides with an automatically named one"},
+		{"different values", And{Eq(Column("a"), NamedArg("foo", 1)), Eq(Column("b"), NamedArg("foo", 2))}, "sqlexpr: named argument foo is used with different values"},
+	}
+	for _, test := range errTests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := BuildWithErr(&namedSQLite, test.expr)
+			if err == nil || err.Error() != test.err {
+				t.Errorf("got error %v, wanted %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestDistinctFromMySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"IsDistinctFrom", IsDistinctFrom(Column("foo"), 42), "NOT (foo <=> ?) [42]"},
+		{"IsNotDistinctFrom", IsNotDistinctFrom(Column("foo"), 42), "foo <=> ? [42]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args := BuildWith(MySQLDialect, test.expr)
+			if a := FormatSQLArgs(sql, args); a != test.expected {
+				t.Errorf("got %q, wanted %q", a, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package sqlexpr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type keyset struct {
+	orderBy OrderBy
+	values  []interface{}
+}
+
+// Keyset returns a condition that matches the rows following the one whose
+// ORDER BY expressions have the given values, for keyset (cursor) pagination.
+// Items of orderBy may be wrapped in Asc or Desc, in any mix. When all items
+// go in the same direction and the dialect supports it, this renders a row
+// value comparison like (a, b) > ($1, $2), otherwise an equivalent OR chain.
+func Keyset(orderBy OrderBy, values ...interface{}) Expr {
+	return keyset{orderBy, values}
+}
+
+// AddKeyset adds a Keyset condition based on the select's OrderBy.
+func (s *Select) AddKeyset(values ...interface{}) {
+	s.AddWhere(Keyset(s.OrderBy, values...))
+}
+
+func (v keyset) AppendToSQLBuilder(b *Builder) {
+	if len(v.orderBy) != len(v.values) {
+		b.AddError(fmt.Errorf("sqlexpr: keyset has %d values for %d ORDER BY items", len(v.values), len(v.orderBy)))
+		b.AppendRaw("FALSE")
+		return
+	}
+	if len(v.orderBy) == 0 {
+		b.AppendRaw("TRUE")
+		return
+	}
+
+	exprs := make([]Expr, len(v.orderBy))
+	ops := make([]string, len(v.orderBy))
+	sameOps := true
+	for i, item := range v.orderBy {
+		exprs[i], ops[i] = item, ">"
+		if o, ok := item.(Ordering); ok {
+			exprs[i] = o.Expr
+			if o.Desc {
+				ops[i] = "<"
+			}
+		}
+		sameOps = sameOps && ops[i] == ops[0]
+	}
+
+	if len(exprs) == 1 {
+		Op(exprs[0], ops[0], v.values[0]).AppendToSQLBuilder(b)
+		return
+	}
+	if sameOps && b.Dialect().RowValues {
+		values := make(Array, len(v.values))
+		copy(values, v.values)
+		Op(Parens(List(exprs)), ops[0], values).AppendToSQLBuilder(b)
+		return
+	}
+
+	params := make([]*Param, len(v.values))
+	for i, value := range v.values {
+		params[i] = NewParam(value)
+	}
+	var or Or
+	for i := range exprs {
+		var and And
+		for j := 0; j < i; j++ {
+			and = append(and, Eq(exprs[j], params[j]))
+		}
+		and = append(and, Op(exprs[i], ops[i], params[i]))
+		or = append(or, and)
+	}
+	or.AppendToSQLBuilder(b)
+}
+
+var ErrInvalidCursor = errors.New("sqlexpr: invalid cursor")
+
+// CursorCodec turns keyset values into opaque tokens that can be handed to
+// clients, and back. Tokens are signed with HMAC-SHA256 using Key, so tampered
+// tokens are rejected.
+type CursorCodec struct {
+	Key []byte
+}
+
+// Encode returns a token holding the JSON-encoded values.
+func (c CursorCodec) Encode(values ...interface{}) (string, error) {
+	if len(c.Key) == 0 {
+		return "", errors.New("sqlexpr: CursorCodec without a key")
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(c.sign(data)), nil
+}
+
+// Decode verifies the token and unmarshals its values into dest, which must
+// be pointers matching the encoded values in number and order. Invalid tokens
+// yield ErrInvalidCursor.
+func (c CursorCodec) Decode(token string, dest ...interface{}) error {
+	if len(c.Key) == 0 {
+		return errors.New("sqlexpr: CursorCodec without a key")
+	}
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(mac, c.sign(data)) {
+		return ErrInvalidCursor
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != len(dest) {
+		return ErrInvalidCursor
+	}
+	for i, item := range raw {
+		if err := json.Unmarshal(item, dest[i]); err != nil {
+			return ErrInvalidCursor
+		}
+	}
+	return nil
+}
+
+func (c CursorCodec) sign(data []byte) []byte {
+	h := hmac.New(sha256.New, c.Key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,74 @@
+package sqlexpr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyset(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		orderBy  OrderBy
+		values   []interface{}
+		expected string
+	}{
+		{"single column", PostgresDialect, OrderBy{Column("id")}, []interface{}{10}, "SELECT * FROM foos WHERE id > $1 ORDER BY id [10]"},
+		{"single desc column", PostgresDialect, OrderBy{Desc(Column("id"))}, []interface{}{10}, "SELECT * FROM foos WHERE id < $1 ORDER BY id DESC [10]"},
+		{"row values", PostgresDialect, OrderBy{Asc(Column("created_at")), Column("id")}, []interface{}{"x", 10}, "SELECT * FROM foos WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id [x, 10]"},
+		{"row values desc", SQLiteDialect, OrderBy{Desc(Column("created_at")), Desc(Column("id"))}, []interface{}{"x", 10}, "SELECT * FROM foos WHERE (created_at, id) < (?, ?) ORDER BY created_at DESC, id DESC [x, 10]"},
+		{"mixed directions", PostgresDialect, OrderBy{Desc(Column("score")), Column("id")}, []interface{}{5, 10}, "SELECT * FROM foos WHERE (score < $1 OR (score = $1 AND id > $2)) ORDER BY score DESC, id [5, 10]"},
+		{"mixed directions with ?", MySQLDialect, OrderBy{Desc(Column("a")), Column("b"), Column("c")}, []interface{}{1, 2, 3}, "SELECT * FROM foos WHERE (a < ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)) ORDER BY a DESC, b, c [1, 1, 2, 1, 2, 3]"},
+		{"no row values", SQLServerDialect, OrderBy{Column("a"), Column("b")}, []interface{}{1, 2}, "SELECT * FROM foos WHERE (a > @p1 OR (a = @p1 AND b > @p2)) ORDER BY a, b [1, 2]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Select{Fields: List{Star}, From: Table("foos"), OrderBy: test.orderBy}
+			s.AddKeyset(test.values...)
+			checkBuild(t, test.dialect, s, test.expected, "")
+		})
+	}
+
+	t.Run("mismatched values", func(t *testing.T) {
+		_, _, err := BuildErr(Keyset(OrderBy{Column("a"), Column("b")}, 1))
+		if err == nil || err.Error() != "sqlexpr: keyset has 1 values for 2 ORDER BY items" {
+			t.Errorf("got error %v", err)
+		}
+	})
+}
+
+func TestCursorCodec(t *testing.T) {
+	c := CursorCodec{Key: []byte("secret")}
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	token, err := c.Encode(tm, int64(42), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		at   time.Time
+		id   int64
+		name string
+	)
+	if err := c.Decode(token, &at, &id, &name); err != nil {
+		t.Fatal(err)
+	}
+	if !at.Equal(tm) || id != 42 || name != "foo" {
+		t.Errorf("got %v, %v, %v", at, id, name)
+	}
+
+	tampered := []byte(token)
+	tampered[3] ^= 1
+	for _, bad := range []string{"", "foo", string(tampered), token + "x"} {
+		if err := c.Decode(bad, &at, &id, &name); err != ErrInvalidCursor {
+			t.Errorf("Decode(%q) returned %v, wanted ErrInvalidCursor", bad, err)
+		}
+	}
+	if err := (CursorCodec{Key: []byte("other")}).Decode(token, &at, &id, &name); err != ErrInvalidCursor {
+		t.Errorf("Decode with another key returned %v, wanted ErrInvalidCursor", err)
+	}
+	if err := c.Decode(token, &at, &id); err != ErrInvalidCursor {
+		t.Errorf("Decode with too few values returned %v, wanted ErrInvalidCursor", err)
+	}
+}
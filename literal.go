@@ -0,0 +1,97 @@
+package sqlexpr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugSQL renders the expression with all values inlined as literals using
+// the default dialect. This is meant for logs and error messages only: never
+// execute the result, use Build and pass the arguments instead.
+func DebugSQL(e Expr) string {
+	return DebugSQLWith(nil, e)
+}
+
+// DebugSQLWith is like DebugSQL, but uses the given dialect.
+func DebugSQLWith(d *Dialect, e Expr) string {
+	b := NewBuilder(d)
+	b.SetInlineArgs(true)
+	b.Append(e)
+	return b.SQL()
+}
+
+// FormatLiteral renders the value as an SQL literal for debugging output.
+// Strings, []byte, time.Time, bools, numbers, nil and driver.Valuer are
+// supported; other values are formatted with fmt and quoted as strings.
+func (d *Dialect) FormatLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case sql.NamedArg:
+		return d.FormatLiteral(v.Value)
+	case driver.Valuer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL"
+		}
+		dv, err := v.Value()
+		if err != nil {
+			return "/* " + strings.Replace(err.Error(), "*/", "* /", -1) + " */ NULL"
+		}
+		return d.FormatLiteral(dv)
+	case []byte:
+		if v == nil {
+			return "NULL"
+		}
+		return fmt.Sprintf(d.BytesLiteral, hex.EncodeToString(v))
+	case time.Time:
+		return d.formatStringLiteral(v.Format(d.TimeLayout))
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		switch {
+		case d.BoolLiterals && rv.Bool():
+			return "TRUE"
+		case d.BoolLiterals:
+			return "FALSE"
+		case rv.Bool():
+			return "1"
+		default:
+			return "0"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return d.formatStringLiteral(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		return strconv.FormatFloat(f, 'g', -1, rv.Type().Bits())
+	case reflect.String:
+		return d.formatStringLiteral(rv.String())
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return d.FormatLiteral(rv.Elem().Interface())
+	default:
+		return d.formatStringLiteral(fmt.Sprint(v))
+	}
+}
+
+func (d *Dialect) formatStringLiteral(s string) string {
+	if d.BackslashEscapes {
+		s = strings.Replace(s, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
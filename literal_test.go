@@ -0,0 +1,62 @@
+package sqlexpr
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestFormatLiteral(t *testing.T) {
+	type status int
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+	var nilPtr *int
+	answer := 42
+
+	tests := []struct {
+		dialect  *Dialect
+		value    interface{}
+		expected string
+	}{
+		{PostgresDialect, nil, "NULL"},
+		{PostgresDialect, 42, "42"},
+		{PostgresDialect, status(3), "3"},
+		{PostgresDialect, uint8(7), "7"},
+		{PostgresDialect, -1.5, "-1.5"},
+		{PostgresDialect, "it's", "'it''s'"},
+		{PostgresDialect, `a\b`, `'a\b'`},
+		{MySQLDialect, `it's a\b`, `'it''s a\\b'`},
+		{PostgresDialect, true, "TRUE"},
+		{SQLServerDialect, true, "1"},
+		{SQLServerDialect, false, "0"},
+		{PostgresDialect, []byte("hi"), `'\x6869'::bytea`},
+		{SQLiteDialect, []byte("hi"), "X'6869'"},
+		{SQLServerDialect, []byte("hi"), "0x6869"},
+		{PostgresDialect, tm, "'2020-01-02 03:04:05.6Z'"},
+		{MySQLDialect, tm, "'2020-01-02 03:04:05.6'"},
+		{PostgresDialect, sql.NullString{}, "NULL"},
+		{PostgresDialect, sql.NullString{String: "x", Valid: true}, "'x'"},
+		{PostgresDialect, sql.Named("foo", 42), "42"},
+		{PostgresDialect, nilPtr, "NULL"},
+		{PostgresDialect, &answer, "42"},
+	}
+	for _, test := range tests {
+		t.Run(test.dialect.Name+" "+test.expected, func(t *testing.T) {
+			if a, e := test.dialect.FormatLiteral(test.value), test.expected; a != e {
+				t.Errorf("got %q, wanted %q", a, e)
+			}
+		})
+	}
+}
+
+func TestDebugSQL(t *testing.T) {
+	expr := Select{
+		Fields: List{Star},
+		From:   Table("foos"),
+		Where:  Where{Eq(Column("foo"), -1), Like(Column("bar"), NewParam("it's")), In(Column("boz"), Array{1, 2}), Eq(Column("x"), NamedArg("x", true))},
+	}
+	a := DebugSQLWith(SQLiteDialect, expr)
+	e := "SELECT * FROM foos WHERE foo = -1 AND bar LIKE 'it''s' AND boz IN (1, 2) AND x = TRUE"
+	if a != e {
+		t.Errorf("got %q, wanted %q", a, e)
+	}
+}
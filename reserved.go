@@ -0,0 +1,82 @@
+package sqlexpr
+
+import (
+	"strings"
+)
+
+const commonReservedWords = `
+	all and any as asc between both by case cast check collate column
+	constraint create cross current_date current_time current_timestamp
+	current_user default delete desc distinct drop else end except exists
+	false fetch for foreign from full grant group having in inner insert
+	intersect into is join leading left like limit natural not null offset on
+	or order outer primary references right select session_user set some
+	table then to trailing true union unique update user using values when
+	where window with
+`
+
+const postgresReservedWords = `
+	analyse analyze array asymmetric current_catalog current_role
+	current_schema deferrable do freeze ilike initially isnull lateral
+	localtime localtimestamp notnull only overlaps placing returning similar
+	symmetric tablesample variadic verbose
+`
+
+const sqliteReservedWords = `
+	abort action after attach autoincrement before begin cascade commit
+	conflict database deferred detach each exclusive explain fail glob if
+	ignore immediate index indexed instead isnull key match no notnull of
+	plan pragma query raise recursive regexp reindex release rename replace
+	restrict returning rollback row savepoint temp temporary transaction
+	trigger vacuum view virtual
+`
+
+const mysqlReservedWords = `
+	accessible add before bigint binary blob call change char character
+	condition continue convert database databases dec decimal declare delayed
+	describe div double dual each elseif enclosed escaped exit explain float
+	force fulltext generated groups if ignore index infile int integer
+	interval iterate key keys kill lag lead leave lines load lock long loop
+	match mod modifies option optionally out outfile partition precision
+	procedure range rank read reads real regexp release rename repeat replace
+	require resignal restrict return revoke rlike row rows schema schemas
+	separator show signal smallint spatial sql ssl starting straight_join
+	tinyint trigger undo unlock unsigned usage use varchar varying virtual
+	while write xor zerofill
+`
+
+const sqlServerReservedWords = `
+	add backup begin break browse bulk cascade checkpoint close clustered
+	commit compute contains containstable continue convert database dbcc
+	deallocate declare deny disk distributed double dump errlvl escape exec
+	execute exit external file fillfactor freetext freetexttable function
+	goto holdlock identity identity_insert identitycol if index key kill
+	lineno load merge national nocheck nonclustered nullif of off offsets
+	open opendatasource openquery openrowset openxml option over percent
+	pivot plan precision print proc procedure public raiserror read readtext
+	reconfigure replication restore restrict return revert revoke rollback
+	rowcount rowguidcol rule save schema securityaudit semantickeyphrasetable
+	setuser shutdown statistics system_user tablesample textsize top tran
+	transaction trigger truncate try_convert tsequal unpivot updatetext use
+	view waitfor while writetext
+`
+
+const oracleReservedWords = `
+	access add audit char cluster comment compress connect current date
+	decimal exclusive file float identified immediate increment index
+	initial integer level lock long maxextents minus mode modify noaudit
+	nocompress nowait number of offline online option pctfree prior public
+	raw rename resource revoke row rowid rownum rows session share size
+	smallint start successful synonym sysdate uid validate varchar varchar2
+	view whenever
+`
+
+func reservedWords(lists ...string) map[string]bool {
+	m := make(map[string]bool)
+	for _, list := range lists {
+		for _, w := range strings.Fields(list) {
+			m[w] = true
+		}
+	}
+	return m
+}
@@ -1,7 +1,10 @@
 package sqlexpr
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 type Setter struct {
@@ -19,14 +22,20 @@ func (v Clause) AppendToSQLBuilder(b *Builder) {
 	if len(v.Items) == 0 {
 		return
 	}
+	b.NewLine()
+	b.Indent()
 	for i, item := range v.Items {
 		if i == 0 {
 			b.AppendRaw(v.Start)
 		} else {
+			if !strings.HasPrefix(v.Separator, ",") {
+				b.NewLine()
+			}
 			b.AppendRaw(v.Separator)
 		}
 		b.Append(item)
 	}
+	b.Unindent()
 }
 
 type Where []Expr
@@ -41,26 +50,326 @@ func (v OrderBy) AppendToSQLBuilder(b *Builder) {
 	Clause{"ORDER BY", ",", v}.AppendToSQLBuilder(b)
 }
 
+type GroupBy []Expr
+
+func (v GroupBy) AppendToSQLBuilder(b *Builder) {
+	if len(v) > 1 && b.Dialect().GroupingSets == WithRollupGroupingSets {
+		for _, item := range v {
+			if _, ok := item.(rollup); ok {
+				b.AddError(fmt.Errorf("sqlexpr: %s only supports ROLLUP as the sole GROUP BY item", b.Dialect().Name))
+			}
+		}
+	}
+	Clause{"GROUP BY", ",", v}.AppendToSQLBuilder(b)
+}
+
+type Having []Expr
+
+func (v Having) AppendToSQLBuilder(b *Builder) {
+	Clause{"HAVING", "AND", v}.AppendToSQLBuilder(b)
+}
+
+type rollup List
+
+// Rollup renders ROLLUP (a, b, ...) for use in GroupBy, or a, b WITH ROLLUP on
+// dialects that only support the latter.
+func Rollup(items ...Expr) Expr {
+	return rollup(items)
+}
+
+func (v rollup) AppendToSQLBuilder(b *Builder) {
+	switch d := b.Dialect(); d.GroupingSets {
+	case StandardGroupingSets:
+		b.AppendRaw("ROLLUP (")
+		b.AppendExpr(List(v))
+		b.AppendRaw(")")
+	case WithRollupGroupingSets:
+		b.AppendExpr(List(v))
+		b.AppendRaw("WITH ROLLUP")
+	default:
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support ROLLUP", d.Name))
+		b.AppendExpr(List(v))
+	}
+}
+
+type cube List
+
+// Cube renders CUBE (a, b, ...) for use in GroupBy.
+func Cube(items ...Expr) Expr {
+	return cube(items)
+}
+
+func (v cube) AppendToSQLBuilder(b *Builder) {
+	if d := b.Dialect(); d.GroupingSets != StandardGroupingSets {
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support CUBE", d.Name))
+	}
+	b.AppendRaw("CUBE (")
+	b.AppendExpr(List(v))
+	b.AppendRaw(")")
+}
+
+type groupingSets []List
+
+// GroupingSets renders GROUPING SETS ((a, b), (a), ()) for use in GroupBy.
+func GroupingSets(sets ...List) Expr {
+	return groupingSets(sets)
+}
+
+func (v groupingSets) AppendToSQLBuilder(b *Builder) {
+	if d := b.Dialect(); d.GroupingSets != StandardGroupingSets {
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support GROUPING SETS", d.Name))
+	}
+	b.AppendRaw("GROUPING SETS (")
+	for i, set := range v {
+		if i > 0 {
+			b.AppendRaw(",")
+		}
+		b.AppendRaw("(")
+		b.AppendExpr(set)
+		b.AppendRaw(")")
+	}
+	b.AppendRaw(")")
+}
+
 type Returning []Expr
 
 func (v Returning) AppendToSQLBuilder(b *Builder) {
 	Clause{"RETURNING", ",", v}.AppendToSQLBuilder(b)
 }
 
+// LimitZero can be used as a limit to get LIMIT 0, since a zero limit means
+// no limit at all.
+const LimitZero = -1
+
 type LimitExpr int
 
 func (v LimitExpr) AppendToSQLBuilder(b *Builder) {
-	if v > 0 {
-		b.AppendRaw("LIMIT")
-		b.AppendRaw(strconv.Itoa(int(v)))
+	Paging{Limit: int(v)}.AppendToSQLBuilder(b)
+}
+
+// Paging renders LIMIT and OFFSET, or OFFSET ... FETCH NEXT depending on the
+// dialect (see Dialect.LimitStyle). A zero Limit means no limit, use
+// LimitZero to limit to zero rows. Ordered says whether the query has an
+// ORDER BY clause, which some dialects require for OFFSET ... FETCH.
+type Paging struct {
+	Limit   int
+	Offset  int
+	Ordered bool
+}
+
+func (v Paging) AppendToSQLBuilder(b *Builder) {
+	limit := v.Limit
+	if limit == LimitZero {
+		limit = 0
+	} else if limit == 0 {
+		limit = -1
+	}
+	if limit < 0 && v.Offset <= 0 {
+		return
+	}
+
+	d := b.Dialect()
+	switch d.LimitStyle {
+	case OffsetFetchLimit:
+		if !v.Ordered && d.OffsetFetchNeedsOrderBy {
+			b.NewLine()
+			b.AppendRaw("ORDER BY (SELECT NULL)")
+		}
+		b.NewLine()
+		b.AppendRaw("OFFSET")
+		b.AppendRaw(strconv.Itoa(maxInt(v.Offset, 0)))
+		b.AppendRaw("ROWS")
+		if limit == 0 && d.NoFetchZero {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support FETCH NEXT 0 ROWS", d.Name))
+		}
+		if limit >= 0 {
+			b.AppendRaw("FETCH NEXT")
+			b.AppendRaw(strconv.Itoa(limit))
+			b.AppendRaw("ROWS ONLY")
+		}
+	default:
+		if limit >= 0 {
+			b.AppendRaw("LIMIT")
+			b.AppendRaw(strconv.Itoa(limit))
+		} else if d.NoLimit != "" {
+			b.AppendRaw("LIMIT")
+			b.AppendRaw(d.NoLimit)
+		}
+		if v.Offset > 0 {
+			b.NewLine()
+			b.AppendRaw("OFFSET")
+			b.AppendRaw(strconv.Itoa(v.Offset))
+		}
 	}
 }
 
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func InnerJoin(a Expr, aCol Column, b Expr, bCol Column) Expr {
-	return Fragment{a, Raw("INNER JOIN"), b, Raw("ON"), Dot(a, aCol), Raw("="), Dot(b, bCol)}
+	return Fragment{a, JoinOn(InnerJoinType, b, Eq(Dot(a, aCol), Dot(b, bCol)))}
+}
+
+type JoinType string
+
+const (
+	InnerJoinType JoinType = "INNER JOIN"
+	LeftJoinType  JoinType = "LEFT JOIN"
+	RightJoinType JoinType = "RIGHT JOIN"
+	FullJoinType  JoinType = "FULL OUTER JOIN"
+	CrossJoinType JoinType = "CROSS JOIN"
+)
+
+// Join renders a JOIN clause that follows a table or another join, e.g. in
+// Select.Joins. The On conditions are combined with AND; setting both On and
+// Using is a build error.
+type Join struct {
+	Type    JoinType
+	Lateral bool
+	Table   Expr
+	On      []Expr
+	Using   []Column
+}
+
+func JoinOn(t JoinType, table Expr, conds ...Expr) Join {
+	return Join{Type: t, Table: table, On: conds}
+}
+
+func JoinUsing(t JoinType, table Expr, cols ...Column) Join {
+	return Join{Type: t, Table: table, Using: cols}
+}
+
+func CrossJoin(table Expr) Join {
+	return Join{Type: CrossJoinType, Table: table}
+}
+
+func (j Join) AppendToSQLBuilder(b *Builder) {
+	t := j.Type
+	if t == "" {
+		t = InnerJoinType
+	}
+	b.NewLine()
+	b.AppendRaw(string(t))
+	if j.Lateral {
+		b.AppendRaw("LATERAL")
+	}
+	b.AppendExpr(j.Table)
+	if t == CrossJoinType && (len(j.On) > 0 || len(j.Using) > 0) {
+		b.AddError(fmt.Errorf("sqlexpr: %s with ON or USING", t))
+	}
+	if len(j.On) > 0 && len(j.Using) > 0 {
+		b.AddError(fmt.Errorf("sqlexpr: %s with both ON and USING", t))
+	}
+	if len(j.Using) > 0 {
+		b.AppendRaw("USING (")
+		for i, col := range j.Using {
+			if i > 0 {
+				b.AppendRaw(",")
+			}
+			b.AppendExpr(col)
+		}
+		b.AppendRaw(")")
+	} else if len(j.On) > 0 {
+		b.AppendRaw("ON")
+		appendConds(b, j.On)
+	} else if t == LeftJoinType || t == RightJoinType || t == FullJoinType {
+		b.AddError(fmt.Errorf("sqlexpr: %s without ON or USING", t))
+	}
+}
+
+// appendConds renders the conditions joined with AND, without parentheses.
+func appendConds(b *Builder, conds []Expr) {
+	for i, cond := range conds {
+		if i > 0 {
+			b.AppendRaw("AND")
+		}
+		b.AppendExpr(cond)
+	}
+}
+
+func isStatement(e Expr) bool {
+	switch e.(type) {
+	case Select, *Select, Compound, *Compound, Insert, *Insert, Update, *Update, Delete, *Delete, Merge, *Merge:
+		return true
+	default:
+		return false
+	}
 }
 
-// Settable represents INSERTs and UPDATEs
+type Materialization int
+
+const (
+	DefaultMaterialization Materialization = iota
+	Materialized
+	NotMaterialized
+)
+
+// CTE is a common table expression: a named statement in a WITH clause.
+// Materialization hints are only rendered on dialects that support them.
+type CTE struct {
+	Name            Table
+	Columns         []Column
+	Materialization Materialization
+	Query           Expr
+}
+
+func (v CTE) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(v.Name)
+	if len(v.Columns) > 0 {
+		b.AppendRaw("(")
+		for i, col := range v.Columns {
+			if i > 0 {
+				b.AppendRaw(",")
+			}
+			b.AppendExpr(col)
+		}
+		b.AppendRaw(")")
+	}
+	b.AppendRaw("AS")
+	if b.Dialect().CTEMaterialization {
+		switch v.Materialization {
+		case Materialized:
+			b.AppendRaw("MATERIALIZED")
+		case NotMaterialized:
+			b.AppendRaw("NOT MATERIALIZED")
+		}
+	}
+	b.AppendSubquery(v.Query)
+}
+
+// With is a WITH clause, available as the With field of every statement.
+type With struct {
+	Recursive bool
+	CTEs      []CTE
+}
+
+func (w *With) Add(name Table, query Expr, columns ...Column) {
+	w.CTEs = append(w.CTEs, CTE{Name: name, Columns: columns, Query: query})
+}
+
+func (w With) AppendToSQLBuilder(b *Builder) {
+	if len(w.CTEs) == 0 {
+		return
+	}
+	b.AppendRaw("WITH")
+	if w.Recursive && !b.Dialect().PlainRecursiveWith {
+		b.AppendRaw("RECURSIVE")
+	}
+	for i, cte := range w.CTEs {
+		if i > 0 {
+			b.AppendRaw(",")
+			b.NewLine()
+		}
+		b.AppendExpr(cte)
+	}
+	b.NewLine()
+}
+
+// Settable represents INSERTs, UPDATEs and MERGE actions
 type Settable interface {
 	Set(field Expr, value interface{})
 }
@@ -75,15 +384,27 @@ type Fieldable interface {
 	AddField(fields ...Expr)
 }
 
+// Select is a SELECT statement. Distinct renders SELECT DISTINCT, and
+// DistinctOn renders PostgreSQL's SELECT DISTINCT ON (...); other OrderBy
+// expressions may only come after all of the DISTINCT ON ones.
 type Select struct {
-	Leading  Expr
-	From     Expr
-	Fields   List
-	Where    Where
-	Grouping Expr
-	OrderBy  OrderBy
-	Limit    int
-	Trailing Expr
+	With       With
+	Distinct   bool
+	DistinctOn []Expr
+	Leading    Expr
+	From       Expr
+	Joins      []Join
+	Fields     List
+	Where      Where
+	GroupBy    GroupBy
+	Having     Having
+	Grouping   Expr
+	Windows    []NamedWindow
+	OrderBy    OrderBy
+	Limit      int
+	Offset     int
+	Lock       *Lock
+	Trailing   Expr
 }
 
 func (s *Select) AddField(fields ...Expr) {
@@ -94,64 +415,572 @@ func (s *Select) AddWhere(conds ...Expr) {
 	s.Where = append(s.Where, conds...)
 }
 
+func (s *Select) AddGroupBy(items ...Expr) {
+	s.GroupBy = append(s.GroupBy, items...)
+}
+
+func (s *Select) AddHaving(conds ...Expr) {
+	s.Having = append(s.Having, conds...)
+}
+
+// AddWindow defines a named window for use with Over.
+func (s *Select) AddWindow(name Table, w Window) {
+	s.Windows = append(s.Windows, NamedWindow{name, w})
+}
+
+func (s *Select) AddJoin(joins ...Join) {
+	s.Joins = append(s.Joins, joins...)
+}
+
 func (s Select) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(s.With)
 	b.AppendRaw("SELECT")
+	if len(s.DistinctOn) > 0 {
+		if s.Distinct {
+			b.AddError(errors.New("sqlexpr: SELECT with both Distinct and DistinctOn"))
+		}
+		s.checkDistinctOn(b)
+		b.AppendRaw("DISTINCT ON (")
+		b.AppendExpr(List(s.DistinctOn))
+		b.AppendRaw(")")
+	} else if s.Distinct {
+		b.AppendRaw("DISTINCT")
+	}
 	b.AppendExpr(s.Leading)
 	b.AppendExpr(s.Fields)
+	b.NewLine()
 	b.AppendRaw("FROM")
 	b.AppendExpr(s.From)
+	for _, j := range s.Joins {
+		b.AppendExpr(j)
+	}
 	b.AppendExpr(s.Where)
+	b.AppendExpr(s.GroupBy)
+	b.AppendExpr(s.Having)
+	b.NewLine()
 	b.AppendExpr(s.Grouping)
+	if len(s.Windows) > 0 {
+		windows := make([]Expr, len(s.Windows))
+		for i, w := range s.Windows {
+			windows[i] = w
+		}
+		b.AppendExpr(Clause{"WINDOW", ",", windows})
+	}
 	b.AppendExpr(s.OrderBy)
-	b.AppendExpr(LimitExpr(s.Limit))
+	b.NewLine()
+	b.AppendExpr(Paging{s.Limit, s.Offset, len(s.OrderBy) > 0})
+	if s.Lock != nil {
+		b.NewLine()
+		b.AppendExpr(s.Lock)
+	}
+	b.NewLine()
 	b.AppendExpr(s.Trailing)
 }
 
+func (s Select) checkDistinctOn(b *Builder) {
+	d := b.Dialect()
+	if !d.DistinctOn {
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support DISTINCT ON", d.Name))
+		return
+	}
+	keys := make(map[string]bool)
+	for _, e := range s.DistinctOn {
+		keys[exprKey(d, e)] = true
+	}
+	seen := make(map[string]bool)
+	for _, e := range s.OrderBy {
+		if o, isOrdering := e.(Ordering); isOrdering {
+			e = o.Expr
+		}
+		if k := exprKey(d, e); keys[k] {
+			seen[k] = true
+		} else {
+			if len(seen) < len(keys) {
+				b.AddError(errors.New("sqlexpr: DISTINCT ON expressions must match the leading ORDER BY expressions"))
+			}
+			return
+		}
+	}
+}
+
+type LockStrength string
+
+const (
+	UpdateLock      LockStrength = "FOR UPDATE"
+	NoKeyUpdateLock LockStrength = "FOR NO KEY UPDATE"
+	ShareLock       LockStrength = "FOR SHARE"
+	KeyShareLock    LockStrength = "FOR KEY SHARE"
+)
+
+type LockWait string
+
+const (
+	WaitLock   LockWait = ""
+	NoWait     LockWait = "NOWAIT"
+	SkipLocked LockWait = "SKIP LOCKED"
+)
+
+// Lock is a row-locking clause of a Select, e.g. FOR UPDATE OF t SKIP LOCKED.
+// An empty Strength means UpdateLock. SQLite ignores locks, and other
+// dialects report the parts they don't support (see Dialect.LockStyle).
+type Lock struct {
+	Strength LockStrength
+	Of       []Table
+	Wait     LockWait
+}
+
+func (v Lock) AppendToSQLBuilder(b *Builder) {
+	d := b.Dialect()
+	strength := v.Strength
+	if strength == "" {
+		strength = UpdateLock
+	}
+	switch d.LockStyle {
+	case StandardLocks:
+	case UpdateShareLocks:
+		if strength != UpdateLock && strength != ShareLock {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support %s", d.Name, strength))
+		}
+	case UpdateLocks:
+		if strength != UpdateLock {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support %s", d.Name, strength))
+		}
+		if len(v.Of) > 0 {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support %s OF tables", d.Name, strength))
+		}
+	case IgnoredLocks:
+		return
+	default:
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support %s", d.Name, strength))
+	}
+	b.AppendRaw(string(strength))
+	for i, table := range v.Of {
+		if i == 0 {
+			b.AppendRaw("OF")
+		} else {
+			b.AppendRaw(",")
+		}
+		b.AppendExpr(table)
+	}
+	b.AppendRaw(string(v.Wait))
+}
+
+type SetOp string
+
+const (
+	UnionOp     SetOp = "UNION"
+	UnionAllOp  SetOp = "UNION ALL"
+	IntersectOp SetOp = "INTERSECT"
+	ExceptOp    SetOp = "EXCEPT"
+)
+
+// Compound combines queries with a set operation like UNION. OrderBy, Limit and
+// Offset apply to the whole result. Members that are compounds themselves or
+// have their own ORDER BY, LIMIT or OFFSET are parenthesized, or wrapped into
+// SELECT * FROM (...) on dialects that don't allow parentheses there.
+type Compound struct {
+	With    With
+	Op      SetOp
+	Queries []Expr
+	OrderBy OrderBy
+	Limit   int
+	Offset  int
+}
+
+func Union(queries ...Expr) Compound {
+	return Compound{Op: UnionOp, Queries: queries}
+}
+
+func UnionAll(queries ...Expr) Compound {
+	return Compound{Op: UnionAllOp, Queries: queries}
+}
+
+func Intersect(queries ...Expr) Compound {
+	return Compound{Op: IntersectOp, Queries: queries}
+}
+
+func Except(queries ...Expr) Compound {
+	return Compound{Op: ExceptOp, Queries: queries}
+}
+
+func (s *Compound) AddQuery(queries ...Expr) {
+	s.Queries = append(s.Queries, queries...)
+}
+
+func (s Compound) AppendToSQLBuilder(b *Builder) {
+	if len(s.Queries) == 0 {
+		b.AddError(errors.New("sqlexpr: compound query without any queries"))
+	}
+	op := s.Op
+	if op == "" {
+		op = UnionOp
+	}
+	b.AppendExpr(s.With)
+	for i, q := range s.Queries {
+		if i > 0 {
+			b.NewLine()
+			b.AppendRaw(string(op))
+			b.NewLine()
+		}
+		if !needsParensInCompound(q) {
+			b.AppendExpr(q)
+		} else if b.Dialect().CompoundParens {
+			b.AppendSubquery(q)
+		} else {
+			b.AppendRaw("SELECT * FROM")
+			b.AppendSubquery(q)
+		}
+	}
+	b.AppendExpr(s.OrderBy)
+	b.AppendExpr(Paging{s.Limit, s.Offset, len(s.OrderBy) > 0})
+}
+
+func needsParensInCompound(q Expr) bool {
+	switch q := q.(type) {
+	case Select:
+		return len(q.OrderBy) > 0 || q.Limit != 0 || q.Offset != 0 || len(q.With.CTEs) > 0
+	case *Select:
+		return needsParensInCompound(*q)
+	case Compound, *Compound:
+		return true
+	default:
+		return false
+	}
+}
+
+// Insert inserts a single row given by Setters, or many rows given either by
+// Columns and Rows or by rows added via AddRow. All rows must set the same
+// columns. Alternatively, Source (e.g. a Select) can provide the rows for
+// the given Columns. Without any values, it inserts DEFAULT VALUES.
 type Insert struct {
-	Leading   Expr
-	Table     Expr
-	Setters   []Setter
-	Trailing  Expr
-	Returning Returning
+	With       With
+	Leading    Expr
+	Table      Expr
+	Setters    []Setter
+	Columns    []Expr
+	Rows       [][]interface{}
+	SetterRows []*SetterRow
+	Source     Expr
+	OnConflict *OnConflict
+	Trailing   Expr
+	Returning  Returning
+}
+
+// SetterRow is a row of a multi-row Insert, see Insert.AddRow.
+type SetterRow struct {
+	Setters []Setter
+}
+
+func (r *SetterRow) Set(field Expr, value interface{}) {
+	r.Setters = append(r.Setters, Setter{field, value})
 }
 
 func (s *Insert) Set(field Expr, value interface{}) {
 	s.Setters = append(s.Setters, Setter{field, value})
 }
 
+// AddRow starts a new row; set its values by calling Set on the result.
+func (s *Insert) AddRow() *SetterRow {
+	r := new(SetterRow)
+	s.SetterRows = append(s.SetterRows, r)
+	return r
+}
+
 func (s *Insert) AddField(fields ...Expr) {
 	s.Returning = append(s.Returning, fields...)
 }
 
+// values returns the column list and the rows of values to insert, with rows
+// given by setters reordered to match the column list.
+func (s Insert) values(d *Dialect) ([]Expr, [][]interface{}, error) {
+	columns, rows := s.Columns, s.Rows
+	setterRows := s.SetterRows
+	if len(s.Setters) > 0 {
+		setterRows = append([]*SetterRow{{s.Setters}}, setterRows...)
+	}
+	if len(setterRows) > 0 {
+		if columns == nil {
+			for _, setter := range setterRows[0].Setters {
+				columns = append(columns, setter.Field)
+			}
+		}
+		indexes := make(map[string]int, len(columns))
+		for i, col := range columns {
+			indexes[exprKey(d, col)] = i
+		}
+		rows = append([][]interface{}(nil), rows...)
+		for _, sr := range setterRows {
+			row := make([]interface{}, len(columns))
+			seen := 0
+			for _, setter := range sr.Setters {
+				i, ok := indexes[exprKey(d, setter.Field)]
+				if !ok || row[i] != nil {
+					return nil, nil, fmt.Errorf("sqlexpr: INSERT row %d sets different columns than row 1", len(rows)+1)
+				}
+				row[i] = Value(setter.Value)
+				seen++
+			}
+			if seen != len(columns) {
+				return nil, nil, fmt.Errorf("sqlexpr: INSERT row %d sets different columns than row 1", len(rows)+1)
+			}
+			rows = append(rows, row)
+		}
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, nil, fmt.Errorf("sqlexpr: INSERT row %d has %d values for %d columns", i+1, len(row), len(columns))
+		}
+	}
+	return columns, rows, nil
+}
+
+func exprKey(d *Dialect, e Expr) string {
+	sql, _ := BuildWith(d, e)
+	return sql
+}
+
+// Batches splits the insert into several statements if its rows need more
+// arguments than the dialect allows in a single statement (see
+// Dialect.MaxArgs). Insert.Exec does this automatically.
+func (s Insert) Batches(d *Dialect) ([]Insert, error) {
+	if d == nil {
+		d = defaultDialect()
+	}
+	columns, rows, err := s.values(d)
+	if err != nil {
+		return nil, err
+	}
+	s.Columns, s.Rows, s.Setters, s.SetterRows = columns, rows, nil, nil
+
+	_, args := BuildWith(d, s)
+	if d.MaxArgs <= 0 || len(args) <= d.MaxArgs {
+		return []Insert{s}, nil
+	}
+
+	rowArgs := make([]int, len(rows))
+	overhead := len(args)
+	for i, row := range rows {
+		_, args := BuildWith(d, Fragment(row))
+		rowArgs[i] = len(args)
+		overhead -= len(args)
+	}
+
+	var batches []Insert
+	start, count := 0, overhead
+	for i, n := range rowArgs {
+		if count+n > d.MaxArgs {
+			if i == start {
+				return nil, fmt.Errorf("sqlexpr: INSERT row %d needs more than %d arguments", i+1, d.MaxArgs)
+			}
+			batch := s
+			batch.Rows = rows[start:i]
+			batches = append(batches, batch)
+			start, count = i, overhead
+		}
+		count += n
+	}
+	batch := s
+	batch.Rows = rows[start:]
+	return append(batches, batch), nil
+}
+
 func (s Insert) AppendToSQLBuilder(b *Builder) {
-	b.AppendRaw("INSERT INTO")
+	columns, rows, err := s.values(b.Dialect())
+	if err != nil {
+		b.AddError(err)
+	} else if s.Source != nil && len(rows) > 0 {
+		b.AddError(errors.New("sqlexpr: INSERT with both a source query and values"))
+	}
+	withBeforeSource := b.Dialect().InsertWithBeforeSource
+	if !withBeforeSource {
+		b.AppendExpr(s.With)
+	} else if len(s.With.CTEs) > 0 && s.Source == nil {
+		b.AddError(fmt.Errorf("sqlexpr: %s only supports WITH in INSERT with a source query", b.Dialect().Name))
+	}
+	if s.OnConflict != nil && len(s.OnConflict.Setters) == 0 && b.Dialect().UpsertStyle == OnDuplicateKeyUpsert {
+		b.AppendRaw("INSERT IGNORE INTO")
+	} else {
+		b.AppendRaw("INSERT INTO")
+	}
 	b.AppendExpr(s.Table)
 	b.AppendExpr(s.Leading)
-	b.AppendRaw("(")
-	for i, setter := range s.Setters {
-		if i > 0 {
-			b.AppendRaw(",")
+	if len(columns) > 0 {
+		b.AppendRaw("(")
+		for i, col := range columns {
+			if i > 0 {
+				b.AppendRaw(",")
+			}
+			b.AppendExpr(col)
 		}
-		b.AppendExpr(setter.Field)
+		b.AppendRaw(")")
 	}
-	b.AppendRaw(")")
-	b.AppendRaw("VALUES")
-	b.AppendRaw("(")
-	for i, setter := range s.Setters {
+	b.NewLine()
+	switch {
+	case s.Source != nil:
+		if withBeforeSource {
+			b.AppendExpr(s.With)
+		}
+		b.AppendExpr(s.Source)
+	case len(rows) > 0:
+		b.AppendRaw("VALUES")
+		b.Indent()
+		for i, row := range rows {
+			if i > 0 {
+				b.AppendRaw(",")
+				b.NewLine()
+			}
+			b.AppendRaw("(")
+			for j, v := range row {
+				if j > 0 {
+					b.AppendRaw(",")
+				}
+				b.Append(v)
+			}
+			b.AppendRaw(")")
+		}
+		b.Unindent()
+	case len(columns) > 0:
+		b.AddError(errors.New("sqlexpr: INSERT with columns but without any values"))
+	default:
+		d := b.Dialect()
+		if d.NoDefaultValues {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support INSERT without values", d.Name))
+		}
+		if d.DefaultValues != "" {
+			b.AppendRaw(d.DefaultValues)
+		} else {
+			b.AppendRaw("DEFAULT VALUES")
+		}
+	}
+	if s.OnConflict != nil {
+		b.AppendExpr(s.OnConflict)
+	}
+	b.NewLine()
+	b.AppendExpr(s.Trailing)
+	b.AppendExpr(s.Returning)
+}
+
+// OnConflict makes an Insert an upsert. Without Setters it does nothing on
+// conflict, otherwise it updates the conflicting row (use Excluded to refer to
+// the values being inserted). Columns or Constraint pick the conflict target,
+// and TargetWhere matches a partial unique index. MySQL ignores the target and
+// uses ON DUPLICATE KEY UPDATE or INSERT IGNORE instead.
+type OnConflict struct {
+	Columns     []Expr
+	Constraint  Table
+	TargetWhere Where
+	Setters     []Setter
+	Where       Where
+}
+
+func (c *OnConflict) Set(field Expr, value interface{}) {
+	c.Setters = append(c.Setters, Setter{field, value})
+}
+
+func (c *OnConflict) AddWhere(conds ...Expr) {
+	c.Where = append(c.Where, conds...)
+}
+
+func (c OnConflict) AppendToSQLBuilder(b *Builder) {
+	d := b.Dialect()
+	switch d.UpsertStyle {
+	case OnConflictUpsert:
+		b.NewLine()
+		b.AppendRaw("ON CONFLICT")
+		if c.Constraint != "" {
+			if !d.ConflictConstraint {
+				b.AddError(fmt.Errorf("sqlexpr: %s does not support ON CONFLICT ON CONSTRAINT", d.Name))
+			}
+			if len(c.TargetWhere) > 0 {
+				b.AddError(errors.New("sqlexpr: ON CONFLICT with both a Constraint and TargetWhere"))
+			}
+			b.AppendRaw("ON CONSTRAINT")
+			b.AppendExpr(c.Constraint)
+		} else if len(c.Columns) > 0 {
+			b.AppendRaw("(")
+			b.AppendExpr(List(c.Columns))
+			b.AppendRaw(")")
+			b.AppendExpr(c.TargetWhere)
+		}
+		if len(c.Setters) == 0 {
+			b.AppendRaw("DO NOTHING")
+			return
+		}
+		if c.Constraint == "" && len(c.Columns) == 0 {
+			b.AddError(errors.New("sqlexpr: ON CONFLICT DO UPDATE without conflict Columns or Constraint"))
+		}
+		b.AppendRaw("DO UPDATE SET")
+		appendSetters(b, c.Setters)
+		b.AppendExpr(c.Where)
+	case OnDuplicateKeyUpsert:
+		if len(c.Where) > 0 {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support WHERE in upserts", d.Name))
+		}
+		if len(c.Setters) > 0 {
+			b.NewLine()
+			b.AppendRaw("ON DUPLICATE KEY UPDATE")
+			appendSetters(b, c.Setters)
+		}
+	default:
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support upserts, use Merge", d.Name))
+	}
+}
+
+type excluded struct {
+	col Column
+}
+
+// Excluded refers to the value that an upsert tried to insert into the given
+// column: EXCLUDED.col, or VALUES(col) in MySQL.
+func Excluded(col Column) Expr {
+	return excluded{col}
+}
+
+func (v excluded) AppendToSQLBuilder(b *Builder) {
+	if b.Dialect().UpsertStyle == OnDuplicateKeyUpsert {
+		b.AppendRaw("VALUES(")
+		b.AppendExpr(v.col)
+		b.AppendRaw(")")
+	} else {
+		Qualified(Raw("EXCLUDED"), v.col).AppendToSQLBuilder(b)
+	}
+}
+
+// unqualifiedSetters strips the table from setter fields made by Table.Col
+// and Qualified, since only MySQL allows SET a.x = ... in UPDATE, and MERGE
+// never allows it.
+func unqualifiedSetters(setters []Setter) []Setter {
+	result := make([]Setter, len(setters))
+	for i, setter := range setters {
+		if q, ok := setter.Field.(qualified); ok && len(q.items) > 0 {
+			setter.Field = q.items[len(q.items)-1]
+		}
+		result[i] = setter
+	}
+	return result
+}
+
+func appendSetters(b *Builder, setters []Setter) {
+	for i, setter := range setters {
 		if i > 0 {
 			b.AppendRaw(",")
 		}
+		b.AppendExpr(setter.Field)
+		b.AppendRaw("=")
 		b.Append(setter.Value)
 	}
-	b.AppendRaw(")")
-	b.AppendExpr(s.Trailing)
-	b.AppendExpr(s.Returning)
 }
 
+// Update updates Table, optionally joined with the tables in From: UPDATE ...
+// FROM in PostgreSQL and SQLite, UPDATE a JOIN b in MySQL (see
+// Dialect.UpdateFrom). Put join conditions into Where. Setter fields made by
+// Table.Col are rendered unqualified except in MySQL.
 type Update struct {
+	With      With
 	Table     Expr
 	Leading   Expr
 	Setters   []Setter
+	From      Expr
 	Where     Where
 	Trailing  Expr
 	Returning Returning
@@ -170,26 +999,52 @@ func (s *Update) AddField(fields ...Expr) {
 }
 
 func (s Update) AppendToSQLBuilder(b *Builder) {
+	if len(s.Setters) == 0 {
+		b.AddError(errors.New("sqlexpr: UPDATE without any SET values"))
+	}
+	d := b.Dialect()
+	b.AppendExpr(s.With)
 	b.AppendRaw("UPDATE")
 	b.AppendExpr(s.Table)
 	b.AppendExpr(s.Leading)
+	if s.From != nil && d.UpdateFrom == JoinMultiTable {
+		b.NewLine()
+		b.AppendRaw("JOIN")
+		b.AppendExpr(s.From)
+	}
+	b.NewLine()
 	b.AppendRaw("SET")
-	for i, setter := range s.Setters {
-		if i > 0 {
-			b.AppendRaw(",")
+	setters := s.Setters
+	if d.UpdateFrom != JoinMultiTable {
+		setters = unqualifiedSetters(setters)
+	}
+	appendSetters(b, setters)
+	if s.From != nil {
+		switch d.UpdateFrom {
+		case StandardMultiTable:
+			b.NewLine()
+			b.AppendRaw("FROM")
+			b.AppendExpr(s.From)
+		case JoinMultiTable:
+			// rendered before SET
+		default:
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support UPDATE with multiple tables", d.Name))
 		}
-		b.AppendExpr(setter.Field)
-		b.AppendRaw("=")
-		b.Append(setter.Value)
 	}
 	b.AppendExpr(s.Where)
+	b.NewLine()
 	b.AppendExpr(s.Trailing)
 	b.AppendExpr(s.Returning)
 }
 
+// Delete deletes from Table, optionally joined with the tables in Using:
+// DELETE ... USING in PostgreSQL, DELETE a FROM a JOIN b in MySQL (see
+// Dialect.DeleteUsing). Put join conditions into Where.
 type Delete struct {
+	With      With
 	Table     Expr
 	Leading   Expr
+	Using     Expr
 	Where     Where
 	Trailing  Expr
 	Returning Returning
@@ -204,10 +1059,183 @@ func (s *Delete) AddField(fields ...Expr) {
 }
 
 func (s Delete) AppendToSQLBuilder(b *Builder) {
-	b.AppendRaw("DELETE FROM")
-	b.AppendExpr(s.Table)
-	b.AppendExpr(s.Leading)
+	d := b.Dialect()
+	b.AppendExpr(s.With)
+	if s.Using != nil && d.DeleteUsing == JoinMultiTable {
+		b.AppendRaw("DELETE")
+		if a, ok := s.Table.(TableAlias); ok {
+			b.AppendExpr(a.Alias)
+		} else {
+			b.AppendExpr(s.Table)
+		}
+		b.AppendRaw("FROM")
+		b.AppendExpr(s.Table)
+		b.AppendExpr(s.Leading)
+		b.NewLine()
+		b.AppendRaw("JOIN")
+		b.AppendExpr(s.Using)
+	} else {
+		b.AppendRaw("DELETE FROM")
+		b.AppendExpr(s.Table)
+		b.AppendExpr(s.Leading)
+	}
+	if s.Using != nil {
+		switch d.DeleteUsing {
+		case StandardMultiTable:
+			b.NewLine()
+			b.AppendRaw("USING")
+			b.AppendExpr(s.Using)
+		case JoinMultiTable:
+			// rendered after the table
+		default:
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support DELETE with multiple tables", d.Name))
+		}
+	}
 	b.AppendExpr(s.Where)
+	b.NewLine()
 	b.AppendExpr(s.Trailing)
 	b.AppendExpr(s.Returning)
 }
+
+// Merge inserts, updates or deletes rows of Table depending on whether they
+// match the rows of Using: MERGE INTO ... USING ... ON ... WHEN [NOT] MATCHED.
+// The WHEN clauses are tried in order, see AddWhenMatched and
+// AddWhenNotMatched. MERGE is unsupported in SQLite and MySQL (see
+// Dialect.MergeStyle).
+type Merge struct {
+	With  With
+	Table Expr
+	Using Expr
+	On    []Expr
+	Whens []*MergeWhen
+}
+
+// MergeWhen is a WHEN clause of a Merge. A matched clause updates the row
+// using Setters, or deletes it if Delete is set. A not matched clause inserts
+// a row with the columns and values of Setters. Where adds AND conditions.
+type MergeWhen struct {
+	Matched bool
+	Where   Where
+	Delete  bool
+	Setters []Setter
+}
+
+func (w *MergeWhen) Set(field Expr, value interface{}) {
+	w.Setters = append(w.Setters, Setter{field, value})
+}
+
+func (w *MergeWhen) AddWhere(conds ...Expr) {
+	w.Where = append(w.Where, conds...)
+}
+
+func (s *Merge) AddOn(conds ...Expr) {
+	s.On = append(s.On, conds...)
+}
+
+// AddWhenMatched adds a WHEN MATCHED [AND conds] clause; call Set on it to
+// update the row, or set Delete to delete it.
+func (s *Merge) AddWhenMatched(conds ...Expr) *MergeWhen {
+	w := &MergeWhen{Matched: true, Where: conds}
+	s.Whens = append(s.Whens, w)
+	return w
+}
+
+// AddWhenNotMatched adds a WHEN NOT MATCHED [AND conds] clause; call Set on it
+// to specify the inserted values.
+func (s *Merge) AddWhenNotMatched(conds ...Expr) *MergeWhen {
+	w := &MergeWhen{Where: conds}
+	s.Whens = append(s.Whens, w)
+	return w
+}
+
+func (s Merge) AppendToSQLBuilder(b *Builder) {
+	d := b.Dialect()
+	if d.MergeStyle == NoMerge {
+		b.AddError(fmt.Errorf("sqlexpr: %s does not support MERGE", d.Name))
+	}
+	if len(s.On) == 0 {
+		b.AddError(errors.New("sqlexpr: MERGE without ON conditions"))
+	}
+	if len(s.Whens) == 0 {
+		b.AddError(errors.New("sqlexpr: MERGE without any WHEN clauses"))
+	}
+	b.AppendExpr(s.With)
+	b.AppendRaw("MERGE INTO")
+	b.AppendExpr(s.Table)
+	b.NewLine()
+	b.AppendRaw("USING")
+	b.AppendExpr(s.Using)
+	b.NewLine()
+	b.AppendRaw("ON")
+	if d.MergeStyle == OracleMerge {
+		b.AppendRaw("(")
+		appendConds(b, s.On)
+		b.AppendRaw(")")
+	} else {
+		appendConds(b, s.On)
+	}
+	for _, w := range s.Whens {
+		b.AppendExpr(w)
+	}
+	if d.MergeStyle == SemicolonMerge {
+		b.AppendRaw(";")
+	}
+}
+
+func (w MergeWhen) AppendToSQLBuilder(b *Builder) {
+	d := b.Dialect()
+	b.NewLine()
+	if w.Matched {
+		b.AppendRaw("WHEN MATCHED")
+	} else {
+		b.AppendRaw("WHEN NOT MATCHED")
+	}
+	if len(w.Where) > 0 && d.MergeStyle != OracleMerge {
+		b.AppendRaw("AND")
+		appendConds(b, w.Where)
+	}
+	b.AppendRaw("THEN")
+	switch {
+	case w.Matched && w.Delete:
+		if len(w.Setters) > 0 {
+			b.AddError(errors.New("sqlexpr: MERGE clause both updates and deletes"))
+		}
+		if d.MergeStyle == OracleMerge {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support DELETE in MERGE", d.Name))
+		}
+		b.AppendRaw("DELETE")
+	case w.Matched:
+		if len(w.Setters) == 0 {
+			b.AddError(errors.New("sqlexpr: MERGE WHEN MATCHED without any SET values"))
+		}
+		b.AppendRaw("UPDATE SET")
+		appendSetters(b, unqualifiedSetters(w.Setters))
+	case w.Delete:
+		b.AddError(errors.New("sqlexpr: MERGE cannot delete rows that are not matched"))
+	case len(w.Setters) == 0:
+		if d.MergeStyle == OracleMerge {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support INSERT DEFAULT VALUES in MERGE", d.Name))
+		}
+		b.AppendRaw("INSERT DEFAULT VALUES")
+	default:
+		b.AppendRaw("INSERT (")
+		for i, setter := range unqualifiedSetters(w.Setters) {
+			if i > 0 {
+				b.AppendRaw(",")
+			}
+			b.AppendExpr(setter.Field)
+		}
+		b.AppendRaw(") VALUES (")
+		for i, setter := range w.Setters {
+			if i > 0 {
+				b.AppendRaw(",")
+			}
+			b.Append(setter.Value)
+		}
+		b.AppendRaw(")")
+	}
+	if len(w.Where) > 0 && d.MergeStyle == OracleMerge {
+		b.AppendRaw("WHERE")
+		appendConds(b, w.Where)
+	}
+}
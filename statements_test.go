@@ -1,6 +1,7 @@
 package sqlexpr
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -13,6 +14,7 @@ func TestStatements(t *testing.T) {
 		{"where 0", Where{}, ""},
 		{"where 1", Where{Eq(Column("foo"), 42)}, "WHERE foo = $1 [42]"},
 		{"where 2", Where{Eq(Column("foo"), 42), Column("is_bar")}, "WHERE foo = $1 AND is_bar [42]"},
+		{"clause without separator", Clause{"X", "", []Expr{Column("a"), Column("b")}}, "X a b"},
 
 		{"simple select", Select{
 			Fields: List{Star},
@@ -28,6 +30,87 @@ func TestStatements(t *testing.T) {
 			Limit:   1,
 		}, "SELECT DISTINCT foo, bar, boz FROM foos INNER JOIN widgets ON foos.widget_id = widgets.id WHERE foo = $1 AND bar IS NOT NULL ORDER BY foo, bar DESC LIMIT 1 [42]"},
 
+		{"joins", Select{
+			Fields: List{Star},
+			From:   Table("foos"),
+			Joins: []Join{
+				JoinOn(LeftJoinType, Table("widgets"), Eq(Dot(Table("widgets"), Column("id")), Dot(Table("foos"), Column("widget_id"))), Gt(Dot(Table("widgets"), Column("size")), 10)),
+				JoinUsing(RightJoinType, Table("bars"), "bar_id", "kind"),
+				JoinOn(FullJoinType, As(Table("bozs"), "b"), Lt(Column("b.x"), Column("foos.x"))),
+				CrossJoin(Table("colors")),
+				{Type: LeftJoinType, Lateral: true, Table: Subquery(Select{Fields: List{Star}, From: Table("tags"), Where: Where{Eq(Column("tags.foo_id"), Column("foos.id"))}, Limit: 3}, "t"), On: []Expr{TRUE}},
+				JoinOn(InnerJoinType, Table("x"), Eq(Column("x.id"), 42)),
+			},
+		}, "SELECT * FROM foos LEFT JOIN widgets ON widgets.id = foos.widget_id AND widgets.size > $1 RIGHT JOIN bars USING (bar_id, kind) FULL OUTER JOIN bozs AS b ON b.x < foos.x CROSS JOIN colors LEFT JOIN LATERAL (SELECT * FROM tags WHERE tags.foo_id = foos.id LIMIT 3) AS t ON TRUE INNER JOIN x ON x.id = $2 [10, 42]"},
+
+		{"table aliases", func() Expr {
+			a, o := Table("accounts").As("a"), Table("order").As("o")
+			return Select{
+				Fields: List{a.Col("id"), o.Col("user")},
+				From:   a,
+				Joins:  []Join{JoinOn(LeftJoinType, o, Eq(o.Col("account_id"), a.Col("id")))},
+				Where:  Where{Eq(Table("order").Col("id"), 1)},
+			}
+		}(), `SELECT a.id, o."user" FROM accounts AS a LEFT JOIN "order" AS o ON o.account_id = a.id WHERE "order".id = $1 [1]`},
+		{"update with table alias", Update{
+			Table:   Table("accounts").As("a"),
+			Setters: []Setter{{Column("name"), "x"}},
+			Where:   Where{Eq(Table("a").Col("id"), 1)},
+		}, "UPDATE accounts AS a SET name = $1 WHERE a.id = $2 [x, 1]"},
+		{"delete with table alias", Delete{
+			Table: Table("user").As("u"),
+			Where: Where{Eq(Table("u").Col("id"), 1)},
+		}, `DELETE FROM "user" AS u WHERE u.id = $1 [1]`},
+
+		{"reserved words", Select{
+			Fields: List{Column("user"), Column("createdAt")},
+			From:   Table("order"),
+		}, `SELECT "user", "createdAt" FROM "order"`},
+
+		{"group by and having", func() Expr {
+			s := Select{Fields: List{Column("kind"), Count(Star)}, From: Table("foos")}
+			s.AddGroupBy(Column("kind"))
+			s.AddHaving(Gt(Count(Star), 1))
+			s.AddGroupBy(Column("color"))
+			s.AddHaving(Lt(Max(Column("size")), 10))
+			return s
+		}(), "SELECT kind, COUNT (*) FROM foos GROUP BY kind, color HAVING COUNT (*) > $1 AND MAX (size) < $2 [1, 10]"},
+		{"rollup", Select{Fields: List{Star}, From: Table("foos"), GroupBy: GroupBy{Rollup(Column("a"), Column("b"))}}, "SELECT * FROM foos GROUP BY ROLLUP (a, b)"},
+		{"cube", Select{Fields: List{Star}, From: Table("foos"), GroupBy: GroupBy{Column("x"), Cube(Column("a"), Column("b"))}}, "SELECT * FROM foos GROUP BY x, CUBE (a, b)"},
+		{"grouping sets", Select{Fields: List{Star}, From: Table("foos"), GroupBy: GroupBy{GroupingSets(List{Column("a"), Column("b")}, List{Column("a")}, List{})}}, "SELECT * FROM foos GROUP BY GROUPING SETS ((a, b), (a), ())"},
+
+		{"with", func() Expr {
+			s := Select{Fields: List{Star}, From: Table("recent"), Where: Where{Eq(Column("kind"), "b")}}
+			s.With.Add("recent", Select{Fields: List{Column("id"), Column("kind")}, From: Table("foos"), Where: Where{Gt(Column("created_at"), "a")}})
+			s.With.CTEs = append(s.With.CTEs, CTE{Name: "big", Materialization: NotMaterialized, Query: Select{Fields: List{Column("id")}, From: Table("bars")}})
+			return s
+		}(), "WITH recent AS (SELECT id, kind FROM foos WHERE created_at > $1), big AS NOT MATERIALIZED (SELECT id FROM bars) SELECT * FROM recent WHERE kind = $2 [a, b]"},
+		{"with recursive", Select{
+			With: With{Recursive: true, CTEs: []CTE{{
+				Name:    "tree",
+				Columns: []Column{"id", "parent_id"},
+				Query:   Fragment{Select{Fields: List{Column("id"), Column("parent_id")}, From: Table("nodes"), Where: Where{Eq(Column("id"), 1)}}, Raw("UNION ALL"), Select{Fields: List{Column("n.id"), Column("n.parent_id")}, From: Table("nodes").As("n"), Joins: []Join{JoinOn(InnerJoinType, Table("tree").As("t"), Eq(Column("n.parent_id"), Column("t.id")))}}},
+			}}},
+			Fields: List{Column("id")},
+			From:   Table("tree"),
+		}, "WITH RECURSIVE tree (id, parent_id) AS (SELECT id, parent_id FROM nodes WHERE id = $1 UNION ALL SELECT n.id, n.parent_id FROM nodes AS n INNER JOIN tree AS t ON n.parent_id = t.id) SELECT id FROM tree [1]"},
+		{"with insert", Insert{
+			With:    With{CTEs: []CTE{{Name: "x", Query: Select{Fields: List{Raw("1")}, From: Table("foos")}}}},
+			Table:   Table("bars"),
+			Setters: []Setter{{Column("foo"), 42}},
+		}, "WITH x AS (SELECT 1 FROM foos) INSERT INTO bars (foo) VALUES ($1) [42]"},
+		{"with update", Update{
+			With:    With{CTEs: []CTE{{Name: "x", Query: Select{Fields: List{Column("id")}, From: Table("foos"), Where: Where{Eq(Column("a"), 1)}}}}},
+			Table:   Table("bars"),
+			Setters: []Setter{{Column("foo"), 2}},
+			Where:   Where{InSubquery(Column("id"), Select{Fields: List{Column("id")}, From: Table("x")})},
+		}, "WITH x AS (SELECT id FROM foos WHERE a = $1) UPDATE bars SET foo = $2 WHERE id IN (SELECT id FROM x) [1, 2]"},
+		{"with delete", Delete{
+			With:  With{CTEs: []CTE{{Name: "x", Materialization: Materialized, Query: Select{Fields: List{Column("id")}, From: Table("foos")}}}},
+			Table: Table("bars"),
+			Where: Where{InSubquery(Column("id"), Select{Fields: List{Column("id")}, From: Table("x")})},
+		}, "WITH x AS MATERIALIZED (SELECT id FROM foos) DELETE FROM bars WHERE id IN (SELECT id FROM x)"},
+
 		{"simple insert", Insert{
 			Table: Table("foos"),
 			Setters: []Setter{
@@ -45,6 +128,37 @@ func TestStatements(t *testing.T) {
 			Returning: Returning{Column("boz")},
 		}, "INSERT INTO foos (foo, bar) VALUES ($1, $2) RETURNING boz [42, test]"},
 
+		{"multi-row insert", Insert{
+			Table:     Table("foos"),
+			Columns:   []Expr{Column("foo"), Column("bar")},
+			Rows:      [][]interface{}{{1, "a"}, {2, NOW}},
+			Returning: Returning{Column("id")},
+		}, "INSERT INTO foos (foo, bar) VALUES ($1, $2), ($3, NOW()) RETURNING id [1, a, 2]"},
+		{"multi-row insert via AddRow", func() Expr {
+			s := Insert{Table: Table("foos")}
+			for i, name := range []string{"a", "b"} {
+				var row Settable = s.AddRow()
+				row.Set(Column("foo"), i)
+				row.Set(Column("bar"), name)
+			}
+			row := s.AddRow()
+			row.Set(Column("bar"), "c")
+			row.Set(Column("foo"), 2)
+			return s
+		}(), "INSERT INTO foos (foo, bar) VALUES ($1, $2), ($3, $4), ($5, $6) [0, a, 1, b, 2, c]"},
+
+		{"insert select", Insert{
+			Table:     Table("foos"),
+			Columns:   []Expr{Column("foo"), Column("bar")},
+			Source:    Select{Fields: List{Column("a"), Value(1)}, From: Table("bars"), Where: Where{Eq(Column("b"), 2)}},
+			Returning: Returning{Column("id")},
+		}, "INSERT INTO foos (foo, bar) SELECT a, $1 FROM bars WHERE b = $2 RETURNING id [1, 2]"},
+		{"insert compound", Insert{
+			Table:  Table("foos"),
+			Source: UnionAll(Select{Fields: List{Star}, From: Table("a")}, Select{Fields: List{Star}, From: Table("b")}),
+		}, "INSERT INTO foos SELECT * FROM a UNION ALL SELECT * FROM b"},
+		{"insert default values", Insert{Table: Table("foos"), Returning: Returning{Column("id")}}, "INSERT INTO foos DEFAULT VALUES RETURNING id"},
+
 		{"simple update", Update{
 			Table: Table("foos"),
 			Setters: []Setter{
@@ -80,3 +194,438 @@ func TestStatements(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiline(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"select", Select{
+			Fields:  List{Column("id"), Column("email")},
+			From:    Table("accounts"),
+			Where:   Where{Or{Like(Column("name"), "x"), Like(Column("notes"), "x")}, Not(Column("deleted"))},
+			OrderBy: OrderBy{Column("id")},
+			Limit:   10,
+		}, `SELECT id, email
+FROM accounts
+WHERE (
+    name LIKE $1
+    OR notes LIKE $2
+  )
+  AND NOT deleted
+ORDER BY id
+LIMIT 10`},
+
+		{"subquery", Select{
+			Fields: List{Star},
+			From:   Table("accounts"),
+			Where:  Where{In(Column("id"), Parens(Select{Fields: List{Column("account_id")}, From: Table("orders"), Where: Where{Eq(Column("paid"), true)}}))},
+		}, `SELECT *
+FROM accounts
+WHERE id IN (
+    SELECT account_id
+    FROM orders
+    WHERE paid = $1
+  )`},
+
+		{"insert", Insert{
+			Table:     Table("foos"),
+			Setters:   []Setter{{Column("foo"), 42}, {Column("bar"), "test"}},
+			Returning: Returning{Column("id")},
+		}, `INSERT INTO foos (foo, bar)
+VALUES ($1, $2)
+RETURNING id`},
+
+		{"update", Update{
+			Table:   Table("foos"),
+			Setters: []Setter{{Column("foo"), 42}},
+			Where:   Where{Eq(Column("id"), 1), Eq(Column("bar"), 2)},
+		}, `UPDATE foos
+SET foo = $1
+WHERE id = $2
+  AND bar = $3`},
+
+		{"delete", Delete{
+			Table: Table("foos"),
+			Where: Where{Eq(Column("id"), 1)},
+		}, `DELETE FROM foos
+WHERE id = $1`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBuilder(PostgresDialect)
+			b.SetIndent("  ")
+			b.Append(test.expr)
+			if a := b.SQL(); a != test.expected {
+				t.Errorf("got:\n%s\n\nwanted:\n%s", a, test.expected)
+			}
+		})
+	}
+}
+
+func TestStatementErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"insert with columns but without values", Insert{Table: Table("foos"), Columns: []Expr{Column("foo")}}, "INSERT INTO foos (foo)", "sqlexpr: INSERT with columns but without any values"},
+		{"insert with query and values", Insert{Table: Table("foos"), Setters: []Setter{{Column("foo"), 1}}, Source: Select{Fields: List{Column("foo")}, From: Table("bars")}}, "INSERT INTO foos (foo) SELECT foo FROM bars", "sqlexpr: INSERT with both a source query and values"},
+		{"left join without condition", Select{Fields: List{Star}, From: Table("foos"), Joins: []Join{{Type: LeftJoinType, Table: Table("bars")}}}, "SELECT * FROM foos LEFT JOIN bars", "sqlexpr: LEFT JOIN without ON or USING"},
+		{"cross join with condition", Select{Fields: List{Star}, From: Table("foos"), Joins: []Join{{Type: CrossJoinType, Table: Table("bars"), On: []Expr{TRUE}}}}, "SELECT * FROM foos CROSS JOIN bars ON TRUE", "sqlexpr: CROSS JOIN with ON or USING"},
+		{"join with on and using", Select{Fields: List{Star}, From: Table("foos"), Joins: []Join{{Type: LeftJoinType, Table: Table("bars"), On: []Expr{TRUE}, Using: []Column{"a"}}}}, "SELECT * FROM foos LEFT JOIN bars USING (a)", "sqlexpr: LEFT JOIN with both ON and USING"},
+		{"insert rows with different columns", func() Expr {
+			s := Insert{Table: Table("foos")}
+			s.AddRow().Set(Column("foo"), 1)
+			s.AddRow().Set(Column("bar"), 2)
+			return s
+		}(), "INSERT INTO foos DEFAULT VALUES", "sqlexpr: INSERT row 2 sets different columns than row 1"},
+		{"insert rows with missing columns", func() Expr {
+			s := Insert{Table: Table("foos")}
+			s.Set(Column("foo"), 1)
+			s.Set(Column("bar"), 1)
+			s.AddRow().Set(Column("foo"), 2)
+			return s
+		}(), "INSERT INTO foos DEFAULT VALUES", "sqlexpr: INSERT row 2 sets different columns than row 1"},
+		{"insert rows with wrong number of values", Insert{Table: Table("foos"), Columns: []Expr{Column("foo"), Column("bar")}, Rows: [][]interface{}{{1, 2}, {3}}}, "INSERT INTO foos DEFAULT VALUES", "sqlexpr: INSERT row 2 has 1 values for 2 columns"},
+		{"update without setters", Update{Table: Table("foos"), Where: Where{Eq(Column("id"), 1)}}, "UPDATE foos SET WHERE id = $1 [1]", "sqlexpr: UPDATE without any SET values"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, nil, test.expr, test.expected, test.err)
+		})
+	}
+}
+
+func TestGroupingSetsDialects(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		groupBy  GroupBy
+		expected string
+		err      string
+	}{
+		{"MySQL rollup", MySQLDialect, GroupBy{Rollup(Column("a"), Column("b"))}, "SELECT * FROM foos GROUP BY a, b WITH ROLLUP", ""},
+		{"MySQL rollup with other items", MySQLDialect, GroupBy{Column("x"), Rollup(Column("a"))}, "SELECT * FROM foos GROUP BY x, a WITH ROLLUP", "sqlexpr: MySQL only supports ROLLUP as the sole GROUP BY item"},
+		{"MySQL cube", MySQLDialect, GroupBy{Cube(Column("a"))}, "SELECT * FROM foos GROUP BY CUBE (a)", "sqlexpr: MySQL does not support CUBE"},
+		{"SQLite rollup", SQLiteDialect, GroupBy{Rollup(Column("a"))}, "SELECT * FROM foos GROUP BY a", "sqlexpr: SQLite does not support ROLLUP"},
+		{"SQLite grouping sets", SQLiteDialect, GroupBy{GroupingSets(List{Column("a")})}, "SELECT * FROM foos GROUP BY GROUPING SETS ((a))", "sqlexpr: SQLite does not support GROUPING SETS"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, Select{Fields: List{Star}, From: Table("foos"), GroupBy: test.groupBy}, test.expected, test.err)
+		})
+	}
+}
+
+func TestPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		limit    int
+		offset   int
+		orderBy  OrderBy
+		expected string
+		err      string
+	}{
+		{"no limit", PostgresDialect, 0, 0, nil, "SELECT * FROM foos", ""},
+		{"limit", PostgresDialect, 10, 0, nil, "SELECT * FROM foos LIMIT 10", ""},
+		{"limit zero", PostgresDialect, LimitZero, 0, nil, "SELECT * FROM foos LIMIT 0", ""},
+		{"limit and offset", PostgresDialect, 10, 20, nil, "SELECT * FROM foos LIMIT 10 OFFSET 20", ""},
+		{"offset", PostgresDialect, 0, 20, nil, "SELECT * FROM foos OFFSET 20", ""},
+		{"SQLite offset", SQLiteDialect, 0, 20, nil, "SELECT * FROM foos LIMIT -1 OFFSET 20", ""},
+		{"SQLite limit and offset", SQLiteDialect, 10, 20, nil, "SELECT * FROM foos LIMIT 10 OFFSET 20", ""},
+		{"MySQL offset", MySQLDialect, 0, 20, nil, "SELECT * FROM foos LIMIT 18446744073709551615 OFFSET 20", ""},
+		{"SQL Server limit", SQLServerDialect, 10, 0, OrderBy{Column("id")}, "SELECT * FROM foos ORDER BY id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", ""},
+		{"SQL Server limit and offset", SQLServerDialect, 10, 20, OrderBy{Column("id")}, "SELECT * FROM foos ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", ""},
+		{"SQL Server offset", SQLServerDialect, 0, 20, OrderBy{Column("id")}, "SELECT * FROM foos ORDER BY id OFFSET 20 ROWS", ""},
+		{"SQL Server unordered", SQLServerDialect, 10, 0, nil, "SELECT * FROM foos ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", ""},
+		{"SQL Server limit zero", SQLServerDialect, LimitZero, 0, OrderBy{Column("id")}, "SELECT * FROM foos ORDER BY id OFFSET 0 ROWS FETCH NEXT 0 ROWS ONLY", "sqlexpr: SQL Server does not support FETCH NEXT 0 ROWS"},
+		{"Oracle limit zero", OracleDialect, LimitZero, 0, nil, "SELECT * FROM foos OFFSET 0 ROWS FETCH NEXT 0 ROWS ONLY", ""},
+		{"Oracle unordered", OracleDialect, 10, 5, nil, "SELECT * FROM foos OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Select{Fields: List{Star}, From: Table("foos"), OrderBy: test.orderBy, Limit: test.limit, Offset: test.offset}
+			checkBuild(t, test.dialect, s, test.expected, test.err)
+		})
+	}
+}
+
+func TestWithMaterializationMySQL(t *testing.T) {
+	s := Select{Fields: List{Star}, From: Table("x")}
+	s.With.CTEs = []CTE{{Name: "x", Materialization: Materialized, Query: Select{Fields: List{Column("id")}, From: Table("foos")}}}
+	e := "WITH x AS (SELECT id FROM foos) SELECT * FROM x"
+	if a, _ := BuildWith(MySQLDialect, s); a != e {
+		t.Errorf("got %q, wanted %q", a, e)
+	}
+}
+
+func TestWithRecursiveDialects(t *testing.T) {
+	s := Select{Fields: List{Column("n")}, From: Table("r")}
+	s.With = With{Recursive: true, CTEs: []CTE{{Name: "r", Columns: []Column{"n"}, Query: Select{Fields: List{Raw("1")}, From: Table("foos")}}}}
+	checkBuild(t, SQLiteDialect, s, "WITH RECURSIVE r (n) AS (SELECT 1 FROM foos) SELECT n FROM r", "")
+	checkBuild(t, SQLServerDialect, s, "WITH r (n) AS (SELECT 1 FROM foos) SELECT n FROM r", "")
+	checkBuild(t, OracleDialect, s, "WITH r (n) AS (SELECT 1 FROM foos) SELECT n FROM r", "")
+}
+
+func TestInsertWithBeforeSource(t *testing.T) {
+	var with With
+	with.Add("x", Select{Fields: List{Column("id")}, From: Table("foos")})
+	s := Insert{With: with, Table: Table("bars"), Columns: []Expr{Column("id")}, Source: Select{Fields: List{Column("id")}, From: Table("x")}}
+	checkBuild(t, MySQLDialect, s, "INSERT INTO bars (id) WITH x AS (SELECT id FROM foos) SELECT id FROM x", "")
+	checkBuild(t, OracleDialect, s, "INSERT INTO bars (id) WITH x AS (SELECT id FROM foos) SELECT id FROM x", "")
+
+	s = Insert{With: with, Table: Table("bars"), Setters: []Setter{{Column("id"), 1}}}
+	checkBuild(t, MySQLDialect, s, "INSERT INTO bars (id) VALUES (?) [1]", "sqlexpr: MySQL only supports WITH in INSERT with a source query")
+}
+
+func TestCompound(t *testing.T) {
+	foos := Select{Fields: List{Column("id")}, From: Table("foos"), Where: Where{Eq(Column("a"), 1)}}
+	bars := Select{Fields: List{Column("id")}, From: Table("bars"), Where: Where{Eq(Column("b"), 2)}}
+	topBozs := Select{Fields: List{Column("id")}, From: Table("bozs"), OrderBy: OrderBy{Desc(Column("score"))}, Limit: 5}
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+	}{
+		{"union", PostgresDialect, Union(foos, bars), "SELECT id FROM foos WHERE a = $1 UNION SELECT id FROM bars WHERE b = $2 [1, 2]"},
+		{"union all", PostgresDialect, UnionAll(foos, bars), "SELECT id FROM foos WHERE a = $1 UNION ALL SELECT id FROM bars WHERE b = $2 [1, 2]"},
+		{"intersect", PostgresDialect, Intersect(foos, &bars), "SELECT id FROM foos WHERE a = $1 INTERSECT SELECT id FROM bars WHERE b = $2 [1, 2]"},
+		{"except", MySQLDialect, Except(foos, bars), "SELECT id FROM foos WHERE a = ? EXCEPT SELECT id FROM bars WHERE b = ? [1, 2]"},
+		{"order by and limit", PostgresDialect, Compound{Op: UnionOp, Queries: []Expr{foos, bars}, OrderBy: OrderBy{Column("id")}, Limit: 10, Offset: 20}, "SELECT id FROM foos WHERE a = $1 UNION SELECT id FROM bars WHERE b = $2 ORDER BY id LIMIT 10 OFFSET 20 [1, 2]"},
+		{"parenthesized members", PostgresDialect, Union(foos, topBozs, Except(bars, foos)), "SELECT id FROM foos WHERE a = $1 UNION (SELECT id FROM bozs ORDER BY score DESC LIMIT 5) UNION (SELECT id FROM bars WHERE b = $2 EXCEPT SELECT id FROM foos WHERE a = $3) [1, 2, 1]"},
+		{"SQLite members", SQLiteDialect, Union(foos, topBozs), "SELECT id FROM foos WHERE a = ? UNION SELECT * FROM (SELECT id FROM bozs ORDER BY score DESC LIMIT 5) [1]"},
+		{"in subquery", PostgresDialect, In(Column("id"), Union(foos, bars)), "id IN (SELECT id FROM foos WHERE a = $1 UNION SELECT id FROM bars WHERE b = $2) [1, 2]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, "")
+		})
+	}
+}
+
+func TestInsertBatches(t *testing.T) {
+	d := *PostgresDialect
+	d.MaxArgs = 5
+
+	s := Insert{Table: Table("foos"), Returning: Returning{Column("id")}}
+	for i := 0; i < 5; i++ {
+		row := s.AddRow()
+		row.Set(Column("foo"), i)
+		row.Set(Column("bar"), NOW)
+		row.Set(Column("boz"), -i)
+	}
+	batches, err := s.Batches(&d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a []string
+	for _, batch := range batches {
+		sql, args := BuildWith(&d, batch)
+		a = append(a, FormatSQLArgs(sql, args))
+	}
+	e := []string{
+		"INSERT INTO foos (foo, bar, boz) VALUES ($1, NOW(), $2), ($3, NOW(), $4) RETURNING id [0, 0, 1, -1]",
+		"INSERT INTO foos (foo, bar, boz) VALUES ($1, NOW(), $2), ($3, NOW(), $4) RETURNING id [2, -2, 3, -3]",
+		"INSERT INTO foos (foo, bar, boz) VALUES ($1, NOW(), $2) RETURNING id [4, -4]",
+	}
+	if strings.Join(a, "\n") != strings.Join(e, "\n") {
+		t.Errorf("got:\n%s\n\nwanted:\n%s", strings.Join(a, "\n"), strings.Join(e, "\n"))
+	}
+
+	d.MaxArgs = 1
+	if _, err := s.Batches(&d); err == nil || err.Error() != "sqlexpr: INSERT row 1 needs more than 1 arguments" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestInsertDefaultValuesDialects(t *testing.T) {
+	checkBuild(t, MySQLDialect, Insert{Table: Table("foos")}, "INSERT INTO foos () VALUES ()", "")
+	checkBuild(t, OracleDialect, Insert{Table: Table("foos")}, "INSERT INTO foos DEFAULT VALUES", "sqlexpr: Oracle does not support INSERT without values")
+}
+
+func TestUpsert(t *testing.T) {
+	upsert := func(c *OnConflict) Insert {
+		s := Insert{Table: Table("foos"), OnConflict: c, Returning: Returning{Column("id")}}
+		s.Set(Column("email"), "a@example.com")
+		s.Set(Column("name"), "A")
+		return s
+	}
+	update := &OnConflict{Columns: []Expr{Column("email")}}
+	update.Set(Column("name"), Excluded("name"))
+	update.Set(Column("visits"), Raw("foos.visits + 1"))
+	updateWhere := &OnConflict{Columns: []Expr{Column("email")}, TargetWhere: Where{Not(Column("deleted"))}}
+	updateWhere.Set(Column("name"), Excluded("name"))
+	updateWhere.AddWhere(NotEq(Column("foos.name"), Excluded("name")))
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"do nothing", PostgresDialect, upsert(&OnConflict{}), "INSERT INTO foos (email, name) VALUES ($1, $2) ON CONFLICT DO NOTHING RETURNING id [a@example.com, A]", ""},
+		{"do nothing on constraint", PostgresDialect, upsert(&OnConflict{Constraint: "foos_email_key"}), "INSERT INTO foos (email, name) VALUES ($1, $2) ON CONFLICT ON CONSTRAINT foos_email_key DO NOTHING RETURNING id [a@example.com, A]", ""},
+		{"do update", PostgresDialect, upsert(update), "INSERT INTO foos (email, name) VALUES ($1, $2) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, visits = foos.visits + 1 RETURNING id [a@example.com, A]", ""},
+		{"do update where", SQLiteDialect, upsert(updateWhere), "INSERT INTO foos (email, name) VALUES (?, ?) ON CONFLICT (email) WHERE NOT deleted DO UPDATE SET name = EXCLUDED.name WHERE foos.name <> EXCLUDED.name RETURNING id [a@example.com, A]", ""},
+		{"do update without target", PostgresDialect, upsert(&OnConflict{Setters: update.Setters}), "INSERT INTO foos (email, name) VALUES ($1, $2) ON CONFLICT DO UPDATE SET name = EXCLUDED.name, visits = foos.visits + 1 RETURNING id [a@example.com, A]", "sqlexpr: ON CONFLICT DO UPDATE without conflict Columns or Constraint"},
+		{"on constraint with target where", PostgresDialect, upsert(&OnConflict{Constraint: "foos_email_key", TargetWhere: Where{Not(Column("deleted"))}}), "INSERT INTO foos (email, name) VALUES ($1, $2) ON CONFLICT ON CONSTRAINT foos_email_key DO NOTHING RETURNING id [a@example.com, A]", "sqlexpr: ON CONFLICT with both a Constraint and TargetWhere"},
+		{"SQLite on constraint", SQLiteDialect, upsert(&OnConflict{Constraint: "foos_email_key"}), "INSERT INTO foos (email, name) VALUES (?, ?) ON CONFLICT ON CONSTRAINT foos_email_key DO NOTHING RETURNING id [a@example.com, A]", "sqlexpr: SQLite does not support ON CONFLICT ON CONSTRAINT"},
+		{"MySQL ignore", MySQLDialect, Insert{Table: Table("foos"), Setters: []Setter{{Column("email"), "a"}}, OnConflict: &OnConflict{}}, "INSERT IGNORE INTO foos (email) VALUES (?) [a]", ""},
+		{"MySQL update", MySQLDialect, Insert{Table: Table("foos"), Setters: []Setter{{Column("email"), "a"}}, OnConflict: update}, "INSERT INTO foos (email) VALUES (?) ON DUPLICATE KEY UPDATE name = VALUES(name), visits = foos.visits + 1 [a]", ""},
+		{"MySQL update where", MySQLDialect, Insert{Table: Table("foos"), Setters: []Setter{{Column("email"), "a"}}, OnConflict: updateWhere}, "INSERT INTO foos (email) VALUES (?) ON DUPLICATE KEY UPDATE name = VALUES(name) [a]", "sqlexpr: MySQL does not support WHERE in upserts"},
+		{"SQL Server", SQLServerDialect, Insert{Table: Table("foos"), Setters: []Setter{{Column("email"), "a"}}, OnConflict: &OnConflict{}}, "INSERT INTO foos (email) VALUES (@p1) [a]", "sqlexpr: SQL Server does not support upserts, use Merge"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}
+
+func TestMultiTable(t *testing.T) {
+	a, b := Table("accounts").As("a"), Table("orders").As("o")
+	update := Update{
+		Table:   a,
+		Setters: []Setter{{a.Col("total"), b.Col("total")}},
+		From:    b,
+		Where:   Where{Eq(b.Col("account_id"), a.Col("id")), Eq(b.Col("id"), 42)},
+	}
+	del := Delete{
+		Table: a,
+		Using: b,
+		Where: Where{Eq(b.Col("account_id"), a.Col("id")), Eq(b.Col("id"), 42)},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"update from", PostgresDialect, update, "UPDATE accounts AS a SET total = o.total FROM orders AS o WHERE o.account_id = a.id AND o.id = $1 [42]", ""},
+		{"SQLite update from", SQLiteDialect, update, "UPDATE accounts AS a SET total = o.total FROM orders AS o WHERE o.account_id = a.id AND o.id = ? [42]", ""},
+		{"MySQL update join", MySQLDialect, update, "UPDATE accounts AS a JOIN orders AS o SET a.total = o.total WHERE o.account_id = a.id AND o.id = ? [42]", ""},
+		{"Oracle update from", OracleDialect, update, "UPDATE accounts a SET total = o.total WHERE o.account_id = a.id AND o.id = :1 [42]", "sqlexpr: Oracle does not support UPDATE with multiple tables"},
+		{"delete using", PostgresDialect, del, "DELETE FROM accounts AS a USING orders AS o WHERE o.account_id = a.id AND o.id = $1 [42]", ""},
+		{"MySQL delete join", MySQLDialect, del, "DELETE a FROM accounts AS a JOIN orders AS o WHERE o.account_id = a.id AND o.id = ? [42]", ""},
+		{"MySQL delete join without alias", MySQLDialect, Delete{Table: Table("accounts"), Using: Table("orders"), Where: Where{Eq(Column("orders.account_id"), Column("accounts.id"))}}, "DELETE accounts FROM accounts JOIN orders WHERE orders.account_id = accounts.id", ""},
+		{"SQLite delete using", SQLiteDialect, del, "DELETE FROM accounts AS a WHERE o.account_id = a.id AND o.id = ? [42]", "sqlexpr: SQLite does not support DELETE with multiple tables"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	target, source := Table("accounts").As("a"), Table("changes").As("c")
+	m := Merge{Table: target, Using: source}
+	m.AddOn(Eq(target.Col("id"), source.Col("id")))
+	m.AddWhenMatched(Eq(source.Col("deleted"), true)).Delete = true
+	m.AddWhenMatched().Set(Column("balance"), source.Col("balance"))
+	ins := m.AddWhenNotMatched()
+	ins.Set(Column("id"), source.Col("id"))
+	ins.Set(Column("balance"), 0)
+
+	oracle := Merge{Table: target, Using: source, On: []Expr{Eq(target.Col("id"), source.Col("id")), Gt(source.Col("balance"), 0)}}
+	oracle.AddWhenMatched(Gt(source.Col("balance"), 10)).Set(Column("balance"), source.Col("balance"))
+	oracle.AddWhenNotMatched().Set(Column("id"), source.Col("id"))
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"merge", PostgresDialect, m, "MERGE INTO accounts AS a USING changes AS c ON a.id = c.id WHEN MATCHED AND c.deleted = $1 THEN DELETE WHEN MATCHED THEN UPDATE SET balance = c.balance WHEN NOT MATCHED THEN INSERT (id, balance) VALUES (c.id, $2) [true, 0]", ""},
+		{"SQL Server merge", SQLServerDialect, m, "MERGE INTO accounts AS a USING changes AS c ON a.id = c.id WHEN MATCHED AND c.deleted = @p1 THEN DELETE WHEN MATCHED THEN UPDATE SET balance = c.balance WHEN NOT MATCHED THEN INSERT (id, balance) VALUES (c.id, @p2); [true, 0]", ""},
+		{"Oracle merge", OracleDialect, oracle, "MERGE INTO accounts a USING changes c ON (a.id = c.id AND c.balance > :1) WHEN MATCHED THEN UPDATE SET balance = c.balance WHERE c.balance > :2 WHEN NOT MATCHED THEN INSERT (id) VALUES (c.id) [0, 10]", ""},
+		{"Oracle default values", OracleDialect, Merge{Table: target, Using: source, On: m.On, Whens: []*MergeWhen{{}}}, "MERGE INTO accounts a USING changes c ON (a.id = c.id) WHEN NOT MATCHED THEN INSERT DEFAULT VALUES", "sqlexpr: Oracle does not support INSERT DEFAULT VALUES in MERGE"},
+		{"Oracle merge delete", OracleDialect, m, "MERGE INTO accounts a USING changes c ON (a.id = c.id) WHEN MATCHED THEN DELETE WHERE c.deleted = :1 WHEN MATCHED THEN UPDATE SET balance = c.balance WHEN NOT MATCHED THEN INSERT (id, balance) VALUES (c.id, :2) [true, 0]", "sqlexpr: Oracle does not support DELETE in MERGE"},
+		{"MySQL merge", MySQLDialect, Merge{Table: target, Using: source, On: m.On, Whens: m.Whens[1:2]}, "MERGE INTO accounts AS a USING changes AS c ON a.id = c.id WHEN MATCHED THEN UPDATE SET balance = c.balance", "sqlexpr: MySQL does not support MERGE"},
+		{"qualified setters", SQLServerDialect, func() Expr {
+			q := Merge{Table: target, Using: source, On: m.On}
+			q.AddWhenMatched().Set(target.Col("balance"), source.Col("balance"))
+			q.AddWhenNotMatched().Set(target.Col("id"), source.Col("id"))
+			return q
+		}(), "MERGE INTO accounts AS a USING changes AS c ON a.id = c.id WHEN MATCHED THEN UPDATE SET balance = c.balance WHEN NOT MATCHED THEN INSERT (id) VALUES (c.id);", ""},
+		{"empty merge", PostgresDialect, Merge{Table: target, Using: source}, "MERGE INTO accounts AS a USING changes AS c ON", "sqlexpr: MERGE without ON conditions; sqlexpr: MERGE without any WHEN clauses"},
+		{"invalid actions", PostgresDialect, Merge{Table: target, Using: source, On: m.On, Whens: []*MergeWhen{{Matched: true}, {Delete: true}}}, "MERGE INTO accounts AS a USING changes AS c ON a.id = c.id WHEN MATCHED THEN UPDATE SET WHEN NOT MATCHED THEN", "sqlexpr: MERGE WHEN MATCHED without any SET values; sqlexpr: MERGE cannot delete rows that are not matched"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}
+
+func TestLock(t *testing.T) {
+	jobs := Table("jobs")
+	sel := func(lock *Lock) Select {
+		return Select{From: jobs, Fields: List{Star}, Where: Where{Eq(Column("state"), "new")}, Limit: 1, Lock: lock}
+	}
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"default strength", PostgresDialect, sel(&Lock{Wait: SkipLocked}), "SELECT * FROM jobs WHERE state = $1 LIMIT 1 FOR UPDATE SKIP LOCKED [new]", ""},
+		{"no key update", PostgresDialect, sel(&Lock{Strength: NoKeyUpdateLock}), "SELECT * FROM jobs WHERE state = $1 LIMIT 1 FOR NO KEY UPDATE [new]", ""},
+		{"share of", PostgresDialect, sel(&Lock{Strength: ShareLock, Of: []Table{jobs, "workers"}, Wait: NoWait}), "SELECT * FROM jobs WHERE state = $1 LIMIT 1 FOR SHARE OF jobs, workers NOWAIT [new]", ""},
+		{"MySQL share", MySQLDialect, sel(&Lock{Strength: ShareLock, Of: []Table{jobs}, Wait: SkipLocked}), "SELECT * FROM jobs WHERE state = ? LIMIT 1 FOR SHARE OF jobs SKIP LOCKED [new]", ""},
+		{"MySQL key share", MySQLDialect, sel(&Lock{Strength: KeyShareLock}), "SELECT * FROM jobs WHERE state = ? LIMIT 1 FOR KEY SHARE [new]", "sqlexpr: MySQL does not support FOR KEY SHARE"},
+		{"SQLite", SQLiteDialect, sel(&Lock{Wait: SkipLocked}), "SELECT * FROM jobs WHERE state = ? LIMIT 1 [new]", ""},
+		{"Oracle", OracleDialect, Select{From: jobs, Fields: List{Star}, Lock: &Lock{Wait: NoWait}}, "SELECT * FROM jobs FOR UPDATE NOWAIT", ""},
+		{"Oracle of", OracleDialect, Select{From: jobs, Fields: List{Star}, Lock: &Lock{Strength: ShareLock, Of: []Table{jobs}}}, "SELECT * FROM jobs FOR SHARE OF jobs", "sqlexpr: Oracle does not support FOR SHARE; sqlexpr: Oracle does not support FOR SHARE OF tables"},
+		{"SQL Server", SQLServerDialect, Select{From: jobs, Fields: List{Star}, Lock: &Lock{}}, "SELECT * FROM jobs FOR UPDATE", "sqlexpr: SQL Server does not support FOR UPDATE"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	account, created := Column("account_id"), Column("created_at")
+	sel := func(orderBy ...Expr) Select {
+		return Select{From: Table("events"), Fields: List{Star}, DistinctOn: []Expr{account}, OrderBy: orderBy}
+	}
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"distinct", MySQLDialect, Select{From: Table("events"), Fields: List{account}, Distinct: true}, "SELECT DISTINCT account_id FROM events", ""},
+		{"distinct on", PostgresDialect, sel(account, Desc(created)), "SELECT DISTINCT ON (account_id) * FROM events ORDER BY account_id, created_at DESC", ""},
+		{"distinct on without order", PostgresDialect, sel(), "SELECT DISTINCT ON (account_id) * FROM events", ""},
+		{"distinct on reordered", PostgresDialect, Select{From: Table("events"), Fields: List{Star}, DistinctOn: []Expr{account, Column("kind")}, OrderBy: OrderBy{Asc(Column("kind")), account, created}}, "SELECT DISTINCT ON (account_id, kind) * FROM events ORDER BY kind ASC, account_id, created_at", ""},
+		{"distinct on mismatch", PostgresDialect, sel(created, account), "SELECT DISTINCT ON (account_id) * FROM events ORDER BY created_at, account_id", "sqlexpr: DISTINCT ON expressions must match the leading ORDER BY expressions"},
+		{"distinct on short order", PostgresDialect, Select{From: Table("events"), Fields: List{Star}, DistinctOn: []Expr{account, created}, OrderBy: OrderBy{account}}, "SELECT DISTINCT ON (account_id, created_at) * FROM events ORDER BY account_id", ""},
+		{"distinct on partial order", PostgresDialect, Select{From: Table("events"), Fields: List{Star}, DistinctOn: []Expr{account, Column("kind")}, OrderBy: OrderBy{account, created}}, "SELECT DISTINCT ON (account_id, kind) * FROM events ORDER BY account_id, created_at", "sqlexpr: DISTINCT ON expressions must match the leading ORDER BY expressions"},
+		{"distinct on with distinct", PostgresDialect, Select{From: Table("events"), Fields: List{Star}, Distinct: true, DistinctOn: []Expr{account}}, "SELECT DISTINCT ON (account_id) * FROM events", "sqlexpr: SELECT with both Distinct and DistinctOn"},
+		{"SQLite distinct on", SQLiteDialect, sel(account), "SELECT DISTINCT ON (account_id) * FROM events ORDER BY account_id", "sqlexpr: SQLite does not support DISTINCT ON"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}
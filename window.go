@@ -0,0 +1,167 @@
+package sqlexpr
+
+import (
+	"fmt"
+)
+
+type over struct {
+	fn     Expr
+	window Expr
+}
+
+// Over renders fn OVER window, where window is a Window, or the Table name of
+// a window defined in Select.Windows.
+func Over(fn Expr, window Expr) Expr {
+	return over{fn, window}
+}
+
+func (v over) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(v.fn)
+	b.AppendRaw("OVER")
+	b.AppendExpr(v.window)
+}
+
+// Window is a window specification: (base PARTITION BY ... ORDER BY ... frame).
+// Base optionally names a window from Select.Windows to build upon.
+type Window struct {
+	Base        Table
+	PartitionBy []Expr
+	OrderBy     OrderBy
+	Frame       *Frame
+}
+
+func (w Window) AppendToSQLBuilder(b *Builder) {
+	b.AppendRaw("(")
+	if w.Base != "" {
+		b.AppendExpr(w.Base)
+	}
+	if len(w.PartitionBy) > 0 {
+		b.AppendRaw("PARTITION BY")
+		b.AppendExpr(List(w.PartitionBy))
+	}
+	if len(w.OrderBy) > 0 {
+		b.AppendRaw("ORDER BY")
+		b.AppendExpr(List(w.OrderBy))
+	}
+	if w.Frame != nil {
+		b.AppendExpr(w.Frame)
+	}
+	b.AppendRaw(")")
+}
+
+// NamedWindow is an item of the WINDOW clause, see Select.Windows.
+type NamedWindow struct {
+	Name   Table
+	Window Window
+}
+
+func (v NamedWindow) AppendToSQLBuilder(b *Builder) {
+	b.AppendExpr(v.Name)
+	b.AppendRaw("AS")
+	b.AppendExpr(v.Window)
+}
+
+type FrameUnits string
+
+const (
+	RowsFrame   FrameUnits = "ROWS"
+	RangeFrame  FrameUnits = "RANGE"
+	GroupsFrame FrameUnits = "GROUPS"
+)
+
+type FrameExclusion string
+
+const (
+	ExcludeNoOthers   FrameExclusion = "EXCLUDE NO OTHERS"
+	ExcludeCurrentRow FrameExclusion = "EXCLUDE CURRENT ROW"
+	ExcludeGroup      FrameExclusion = "EXCLUDE GROUP"
+	ExcludeTies       FrameExclusion = "EXCLUDE TIES"
+)
+
+const (
+	UnboundedPreceding = Raw("UNBOUNDED PRECEDING")
+	CurrentRow         = Raw("CURRENT ROW")
+	UnboundedFollowing = Raw("UNBOUNDED FOLLOWING")
+)
+
+// Preceding is a frame bound n rows, values or groups before the current row.
+func Preceding(n interface{}) Expr {
+	return Fragment{n, Raw("PRECEDING")}
+}
+
+// Following is a frame bound n rows, values or groups after the current row.
+func Following(n interface{}) Expr {
+	return Fragment{n, Raw("FOLLOWING")}
+}
+
+// Frame is the frame clause of a Window, e.g. ROWS BETWEEN 1 PRECEDING AND
+// CURRENT ROW. Units default to ROWS, and Start to UNBOUNDED PRECEDING; a nil
+// End renders the short form without BETWEEN. GROUPS and Exclude are only
+// supported by some dialects (see Dialect.FullWindowFrames).
+type Frame struct {
+	Units   FrameUnits
+	Start   Expr
+	End     Expr
+	Exclude FrameExclusion
+}
+
+func (f Frame) AppendToSQLBuilder(b *Builder) {
+	d := b.Dialect()
+	units, start := f.Units, f.Start
+	if units == "" {
+		units = RowsFrame
+	}
+	if start == nil {
+		start = UnboundedPreceding
+	}
+	if !d.FullWindowFrames {
+		if units == GroupsFrame {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support GROUPS window frames", d.Name))
+		}
+		if f.Exclude != "" {
+			b.AddError(fmt.Errorf("sqlexpr: %s does not support EXCLUDE in window frames", d.Name))
+		}
+	}
+	b.AppendRaw(string(units))
+	if f.End != nil {
+		b.AppendRaw("BETWEEN")
+		b.AppendExpr(start)
+		b.AppendRaw("AND")
+		b.AppendExpr(f.End)
+	} else {
+		b.AppendExpr(start)
+	}
+	b.AppendRaw(string(f.Exclude))
+}
+
+func RowNumber() Expr {
+	return Func("ROW_NUMBER")
+}
+
+func Rank() Expr {
+	return Func("RANK")
+}
+
+func DenseRank() Expr {
+	return Func("DENSE_RANK")
+}
+
+func Ntile(n interface{}) Expr {
+	return Func("NTILE", n)
+}
+
+func FirstValue(v Expr) Expr {
+	return Func("FIRST_VALUE", v)
+}
+
+// Lag returns the value of v in an earlier row; args are the optional offset
+// (1 by default) and the default value.
+func Lag(v Expr, args ...interface{}) Expr {
+	return Func("LAG", append([]interface{}{v}, args...)...)
+}
+
+// Lead returns the value of v in a later row; args are the optional offset
+// (1 by default) and the default value.
+func Lead(v Expr, args ...interface{}) Expr {
+	return Func("LEAD", append([]interface{}{v}, args...)...)
+}
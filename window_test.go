@@ -0,0 +1,42 @@
+package sqlexpr
+
+import (
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	account, created, amount := Column("account_id"), Column("created_at"), Column("amount")
+	byAccount := Window{PartitionBy: []Expr{account}, OrderBy: OrderBy{created}}
+
+	named := Select{From: Table("payments"), Fields: List{
+		As(Over(RowNumber(), Table("w")), "n"),
+		Over(Func("SUM", amount), Window{Base: "w", Frame: &Frame{End: CurrentRow}}),
+	}}
+	named.AddWindow("w", byAccount)
+
+	tests := []struct {
+		name     string
+		dialect  *Dialect
+		expr     Expr
+		expected string
+		err      string
+	}{
+		{"row number", PostgresDialect, Over(RowNumber(), byAccount), "ROW_NUMBER () OVER (PARTITION BY account_id ORDER BY created_at)", ""},
+		{"empty window", PostgresDialect, Over(Rank(), Window{}), "RANK () OVER ()", ""},
+		{"dense rank", PostgresDialect, Over(DenseRank(), Window{OrderBy: OrderBy{Desc(amount)}}), "DENSE_RANK () OVER (ORDER BY amount DESC)", ""},
+		{"ntile", PostgresDialect, Over(Ntile(4), Window{OrderBy: OrderBy{amount}}), "NTILE ($1) OVER (ORDER BY amount) [4]", ""},
+		{"lag", PostgresDialect, Over(Lag(amount), byAccount), "LAG (amount) OVER (PARTITION BY account_id ORDER BY created_at)", ""},
+		{"lead", PostgresDialect, Over(Lead(amount, 2, 0), byAccount), "LEAD (amount, $1, $2) OVER (PARTITION BY account_id ORDER BY created_at) [2, 0]", ""},
+		{"first value", PostgresDialect, Over(FirstValue(amount), byAccount), "FIRST_VALUE (amount) OVER (PARTITION BY account_id ORDER BY created_at)", ""},
+		{"rows frame", PostgresDialect, Over(Func("SUM", amount), Window{OrderBy: OrderBy{created}, Frame: &Frame{Start: Preceding(2), End: CurrentRow}}), "SUM (amount) OVER (ORDER BY created_at ROWS BETWEEN $1 PRECEDING AND CURRENT ROW) [2]", ""},
+		{"short frame", SQLiteDialect, Over(Func("SUM", amount), Window{OrderBy: OrderBy{created}, Frame: &Frame{Units: RangeFrame}}), "SUM (amount) OVER (ORDER BY created_at RANGE UNBOUNDED PRECEDING)", ""},
+		{"groups frame", SQLiteDialect, Over(Func("SUM", amount), Window{OrderBy: OrderBy{created}, Frame: &Frame{Units: GroupsFrame, Start: CurrentRow, End: UnboundedFollowing, Exclude: ExcludeTies}}), "SUM (amount) OVER (ORDER BY created_at GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING EXCLUDE TIES)", ""},
+		{"MySQL groups frame", MySQLDialect, Over(Func("SUM", amount), Window{Frame: &Frame{Units: GroupsFrame, End: Following(1), Exclude: ExcludeCurrentRow}}), "SUM (amount) OVER (GROUPS BETWEEN UNBOUNDED PRECEDING AND ? FOLLOWING EXCLUDE CURRENT ROW) [1]", "sqlexpr: MySQL does not support GROUPS window frames; sqlexpr: MySQL does not support EXCLUDE in window frames"},
+		{"named window", PostgresDialect, named, "SELECT ROW_NUMBER () OVER w AS n, SUM (amount) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM payments WINDOW w AS (PARTITION BY account_id ORDER BY created_at)", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkBuild(t, test.dialect, test.expr, test.expected, test.err)
+		})
+	}
+}